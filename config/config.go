@@ -27,6 +27,35 @@ type Config struct {
 	App struct {
 		URL string `yaml:"url"` // Frontend base URL for email links
 	} `yaml:"app"`
+	Storage struct {
+		Driver   string `yaml:"driver"`    // local (default) or s3
+		LocalDir string `yaml:"local_dir"` // root directory of the local driver
+		S3       struct {
+			Endpoint  string `yaml:"endpoint"` // host[:port], e.g. localhost:9000 for MinIO
+			Region    string `yaml:"region"`
+			Bucket    string `yaml:"bucket"`
+			AccessKey string `yaml:"access_key"`
+			SecretKey string `yaml:"secret_key"`
+			UseSSL    bool   `yaml:"use_ssl"`
+		} `yaml:"s3"`
+	} `yaml:"storage"`
+	Attachments struct {
+		MaxSizeMB int `yaml:"max_size_mb"` // per file, defaults to 10
+	} `yaml:"attachments"`
+	Trash struct {
+		RetentionDays int `yaml:"retention_days"` // deleted expenses are purged after this many days; 0 keeps them
+	} `yaml:"trash"`
+	ExchangeRates struct {
+		Providers  string `yaml:"providers"`   // comma-separated, tried in order; defaults to fawazahmed0
+		StaticFile string `yaml:"static_file"` // YAML or JSON file read by the static provider
+		HTTP       struct {
+			URL        string `yaml:"url"`         // JSON endpoint, e.g. a local mock server
+			Base       string `yaml:"base"`        // currency the rates are quoted against when the response has no base
+			RatesField string `yaml:"rates_field"` // dotted path of the rates object, defaults to rates
+			DateField  string `yaml:"date_field"`  // dotted path of the rates date, defaults to date
+			BaseField  string `yaml:"base_field"`  // dotted path of the base currency, defaults to base
+		} `yaml:"http"`
+	} `yaml:"exchange_rates"`
 	Env string `yaml:"env"` // Environment: dev or prod
 }
 
@@ -57,6 +86,8 @@ func LoadConfig() *Config {
 		"brevo_api_key_set", cfg.Brevo.APIKey != "",
 		"brevo_from", cfg.Brevo.From,
 		"app_url", cfg.App.URL,
+		"storage_driver", cfg.Storage.Driver,
+		"exchange_rate_providers", cfg.ExchangeRates.Providers,
 	)
 	return cfg
 }
@@ -0,0 +1,299 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"mindoh-service/common/utils"
+	"mindoh-service/internal/auth"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AccountHandler handles HTTP requests for accounts
+type AccountHandler struct {
+	Service *AccountService
+}
+
+func NewAccountHandler(service *AccountService) *AccountHandler {
+	return &AccountHandler{Service: service}
+}
+
+// CreateAccount godoc
+// @Summary Create an account
+// @Description Create a wallet or bank account that expenses can be recorded against
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param account body dto.AccountCreateRequest true "Account details"
+// @Success 201 {object} dto.AccountResponse "Account created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 409 {object} map[string]interface{} "Account name already exists"
+// @Security BearerAuth
+// @Router /accounts [post]
+func (h *AccountHandler) CreateAccount(c *gin.Context) {
+	var req dto.AccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own accounts"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.Currency == "" {
+		req.Currency = "VND"
+	}
+	account := dbmodel.Account{
+		UserID:         req.UserID,
+		Name:           req.Name,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+	}
+	if err := h.Service.CreateAccount(&account); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toAccountResponse(&account, account.OpeningBalance))
+}
+
+// ListAccounts godoc
+// @Summary List accounts
+// @Description Get accounts with their current balance
+// @Tags accounts
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param include_archived query bool false "Include archived accounts (default: false)"
+// @Success 200 {array} dto.AccountResponse "List of accounts"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /accounts [get]
+func (h *AccountHandler) ListAccounts(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.AccountFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own accounts"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	accounts, err := h.Service.ListAccounts(filter.UserID, filter.IncludeArchived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch accounts"})
+		return
+	}
+	balances, err := h.Service.Balances(accounts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balances"})
+		return
+	}
+	c.JSON(http.StatusOK, toAccountResponseList(accounts, balances))
+}
+
+// GetAccount godoc
+// @Summary Get an account
+// @Description Get an account with its current balance
+// @Tags accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} dto.AccountResponse "Account"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{id} [get]
+func (h *AccountHandler) GetAccount(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	account, err := h.Service.GetAccountByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && account.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own accounts"})
+		return
+	}
+	balances, err := h.Service.Balances([]dbmodel.Account{*account})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance"})
+		return
+	}
+	c.JSON(http.StatusOK, toAccountResponse(account, balances[account.ID]))
+}
+
+// GetBalanceHistory godoc
+// @Summary Get account running balance
+// @Description Get the running balance of an account for each day with activity in the date range
+// @Tags accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.AccountBalanceHistory "Running balance"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{id}/balance [get]
+func (h *AccountHandler) GetBalanceHistory(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	var filter dto.AccountBalanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	for _, d := range []string{filter.From, filter.To} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	account, err := h.Service.GetAccountByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && account.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own accounts"})
+		return
+	}
+	history, err := h.Service.BalanceHistory(account, filter.From, filter.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance"})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// UpdateAccount godoc
+// @Summary Update an account
+// @Description Rename, change currency or opening balance, or archive/unarchive an account
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param account body dto.AccountUpdateRequest true "Account update details"
+// @Success 200 {object} dto.AccountResponse "Account updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Failure 409 {object} map[string]interface{} "Account name already exists"
+// @Security BearerAuth
+// @Router /accounts/{id} [put]
+func (h *AccountHandler) UpdateAccount(c *gin.Context) {
+	var req dto.AccountUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	account, err := h.Service.GetAccountByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && account.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own accounts"})
+		return
+	}
+
+	fields := map[string]interface{}{}
+	if req.Name != nil {
+		account.Name = *req.Name
+		fields["name"] = *req.Name
+	}
+	if req.Currency != nil {
+		account.Currency = *req.Currency
+		fields["currency"] = *req.Currency
+	}
+	if req.OpeningBalance != nil {
+		account.OpeningBalance = *req.OpeningBalance
+		fields["opening_balance"] = *req.OpeningBalance
+	}
+	if req.Archived != nil {
+		account.Archived = *req.Archived
+		fields["archived"] = *req.Archived
+	}
+	if len(fields) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+
+	if err := h.Service.UpdateAccountFields(account, fields); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	balances, err := h.Service.Balances([]dbmodel.Account{*account})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance"})
+		return
+	}
+	c.JSON(http.StatusOK, toAccountResponse(account, balances[account.ID]))
+}
+
+// DeleteAccount godoc
+// @Summary Delete an account
+// @Description Delete an account without expenses. Accounts with history must be archived instead.
+// @Tags accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} map[string]interface{} "Account deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Failure 409 {object} map[string]interface{} "Account has expenses"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{id} [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	account, err := h.Service.GetAccountByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && account.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own accounts"})
+		return
+	}
+	if err := h.Service.DeleteAccount(account.ID); err != nil {
+		if errors.Is(err, ErrAccountInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+}
@@ -0,0 +1,28 @@
+package account
+
+import (
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+
+	"github.com/shopspring/decimal"
+)
+
+func toAccountResponse(a *dbmodel.Account, balance decimal.Decimal) dto.AccountResponse {
+	return dto.AccountResponse{
+		ID:             a.ID,
+		UserID:         a.UserID,
+		Name:           a.Name,
+		Currency:       a.Currency,
+		OpeningBalance: a.OpeningBalance,
+		Archived:       a.Archived,
+		Balance:        balance,
+	}
+}
+
+func toAccountResponseList(accounts []dbmodel.Account, balances map[uint]decimal.Decimal) []dto.AccountResponse {
+	result := make([]dto.AccountResponse, len(accounts))
+	for i := range accounts {
+		result[i] = toAccountResponse(&accounts[i], balances[accounts[i].ID])
+	}
+	return result
+}
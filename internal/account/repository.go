@@ -0,0 +1,116 @@
+package account
+
+import (
+	dbmodel "mindoh-service/internal/db"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// AccountRepository handles DB operations for accounts
+type AccountRepository struct {
+	DB *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{DB: db}
+}
+
+func (r *AccountRepository) GetByID(id uint) (*dbmodel.Account, error) {
+	var account dbmodel.Account
+	err := r.DB.First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetByUserAndName looks up a user's account by name, ignoring case.
+func (r *AccountRepository) GetByUserAndName(userID uint, name string) (*dbmodel.Account, error) {
+	var account dbmodel.Account
+	err := r.DB.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AccountRepository) Create(account *dbmodel.Account) error {
+	return r.DB.Create(account).Error
+}
+
+func (r *AccountRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.DB.Model(&dbmodel.Account{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *AccountRepository) Delete(id uint) error {
+	return r.DB.Delete(&dbmodel.Account{}, id).Error
+}
+
+func (r *AccountRepository) ListByUser(userID uint, includeArchived bool) ([]dbmodel.Account, error) {
+	var accounts []dbmodel.Account
+	query := r.DB.Order("archived asc, name asc")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if !includeArchived {
+		query = query.Where("archived = ?", false)
+	}
+	err := query.Find(&accounts).Error
+	return accounts, err
+}
+
+// CountExpenses returns how many (non-deleted) expenses reference the account.
+func (r *AccountRepository) CountExpenses(accountID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&dbmodel.Expense{}).Where("account_id = ?", accountID).Count(&count).Error
+	return count, err
+}
+
+// AmountSum is a SUM(amount) for one account and native currency,
+// optionally bucketed by date.
+type AmountSum struct {
+	AccountID uint            `gorm:"column:account_id"`
+	Date      string          `gorm:"column:date"`
+	Currency  string          `gorm:"column:currency"`
+	Total     decimal.Decimal `gorm:"column:total"`
+}
+
+// SumsByAccount returns per-account, per-currency totals for the given accounts.
+func (r *AccountRepository) SumsByAccount(accountIDs []uint) (rows []AmountSum, err error) {
+	if len(accountIDs) == 0 {
+		return
+	}
+	err = r.DB.Model(&dbmodel.Expense{}).
+		Select("account_id, currency, SUM(amount) AS total").
+		Where("account_id IN ?", accountIDs).
+		Group("account_id, currency").
+		Scan(&rows).Error
+	return
+}
+
+// SumsBefore returns per-currency totals of the account strictly before date.
+func (r *AccountRepository) SumsBefore(accountID uint, date string) (rows []AmountSum, err error) {
+	err = r.DB.Model(&dbmodel.Expense{}).
+		Select("account_id, currency, SUM(amount) AS total").
+		Where("account_id = ? AND date < ?", accountID, date).
+		Group("account_id, currency").
+		Scan(&rows).Error
+	return
+}
+
+// DailySums returns per-day, per-currency totals of the account within [from, to].
+// Empty bounds are open.
+func (r *AccountRepository) DailySums(accountID uint, from, to string) (rows []AmountSum, err error) {
+	q := r.DB.Model(&dbmodel.Expense{}).
+		Select("account_id, date, currency, SUM(amount) AS total").
+		Where("account_id = ?", accountID)
+	if from != "" {
+		q = q.Where("date >= ?", from)
+	}
+	if to != "" {
+		q = q.Where("date <= ?", to)
+	}
+	err = q.Group("account_id, date, currency").Order("date asc").Scan(&rows).Error
+	return
+}
@@ -0,0 +1,22 @@
+package account
+
+import (
+	"mindoh-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAccountRoutes(r *gin.Engine, a auth.IAuthService, service *AccountService, resolveUser func(string) (uint, error)) {
+	handler := NewAccountHandler(service)
+
+	group := r.Group("/api/accounts")
+	group.Use(a.AuthMiddleware(resolveUser))
+	{
+		group.POST("/", handler.CreateAccount)
+		group.GET("/", handler.ListAccounts)
+		group.GET("/:id", handler.GetAccount)
+		group.GET("/:id/balance", handler.GetBalanceHistory)
+		group.PUT("/:id", handler.UpdateAccount)
+		group.DELETE("/:id", handler.DeleteAccount)
+	}
+}
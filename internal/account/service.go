@@ -0,0 +1,166 @@
+package account
+
+import (
+	"errors"
+	"strings"
+
+	"mindoh-service/internal/currency"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrAccountInUse is returned when deleting an account that still has expenses.
+var ErrAccountInUse = errors.New("account has expenses; archive it instead")
+
+// AccountService handles business logic for accounts
+type AccountService struct {
+	Repo  *AccountRepository
+	Rates *currency.RateHistory // live rates and today's manual overrides
+}
+
+func NewAccountService(repo *AccountRepository, rates *currency.RateHistory) *AccountService {
+	return &AccountService{Repo: repo, Rates: rates}
+}
+
+func (s *AccountService) CreateAccount(account *dbmodel.Account) error {
+	if err := validateAccount(account); err != nil {
+		return err
+	}
+	return s.Repo.Create(account)
+}
+
+// UpdateAccountFields updates only the explicitly provided fields for an account.
+// account is the in-memory state with the changes applied (used for validation).
+func (s *AccountService) UpdateAccountFields(account *dbmodel.Account, fields map[string]interface{}) error {
+	if err := validateAccount(account); err != nil {
+		return err
+	}
+	// Store the values as normalized by validateAccount.
+	if _, ok := fields["name"]; ok {
+		fields["name"] = account.Name
+	}
+	if _, ok := fields["currency"]; ok {
+		fields["currency"] = account.Currency
+	}
+	return s.Repo.UpdateFields(account.ID, fields)
+}
+
+func (s *AccountService) GetAccountByID(id uint) (*dbmodel.Account, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *AccountService) ListAccounts(userID uint, includeArchived bool) ([]dbmodel.Account, error) {
+	return s.Repo.ListByUser(userID, includeArchived)
+}
+
+// DeleteAccount deletes an account that has no expenses. Accounts with
+// history should be archived so their balances stay reportable.
+func (s *AccountService) DeleteAccount(id uint) error {
+	count, err := s.Repo.CountExpenses(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAccountInUse
+	}
+	return s.Repo.Delete(id)
+}
+
+// Balances returns the current balance of each account in its own currency:
+// the opening balance plus every expense and income recorded against it.
+// Rows in another currency are converted at today's rates: a manual override
+// of the pair, else the live exchange rate.
+func (s *AccountService) Balances(accounts []dbmodel.Account) (map[uint]decimal.Decimal, error) {
+	ids := make([]uint, len(accounts))
+	byID := make(map[uint]*dbmodel.Account, len(accounts))
+	balances := make(map[uint]decimal.Decimal, len(accounts))
+	for i := range accounts {
+		ids[i] = accounts[i].ID
+		byID[accounts[i].ID] = &accounts[i]
+		balances[accounts[i].ID] = accounts[i].OpeningBalance
+	}
+	rows, err := s.Repo.SumsByAccount(ids)
+	if err != nil {
+		return nil, err
+	}
+	converters := make(map[string]*currency.Converter)
+	for _, row := range rows {
+		acc := byID[row.AccountID]
+		conv, ok := converters[acc.Currency]
+		if !ok {
+			if conv, err = s.currentConverter(acc.Currency); err != nil {
+				return nil, err
+			}
+			converters[acc.Currency] = conv
+		}
+		balances[row.AccountID] = balances[row.AccountID].Add(conv.Convert(row.Total, row.Currency, ""))
+	}
+	return balances, nil
+}
+
+// BalanceHistory returns the running balance of the account for each day with
+// activity within [from, to].
+func (s *AccountService) BalanceHistory(account *dbmodel.Account, from, to string) (*dto.AccountBalanceHistory, error) {
+	conv, err := s.currentConverter(account.Currency)
+	if err != nil {
+		return nil, err
+	}
+	start := account.OpeningBalance
+	if from != "" {
+		before, err := s.Repo.SumsBefore(account.ID, from)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range before {
+			start = start.Add(conv.Convert(row.Total, row.Currency, ""))
+		}
+	}
+	rows, err := s.Repo.DailySums(account.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]dto.AccountBalancePoint, 0)
+	balance := start
+	for _, row := range rows {
+		change := conv.Convert(row.Total, row.Currency, "")
+		balance = balance.Add(change)
+		// Rows are ordered by date; merge multiple currencies on the same day.
+		if n := len(points); n > 0 && points[n-1].Date == row.Date {
+			points[n-1].Change = points[n-1].Change.Add(change)
+			points[n-1].Balance = balance
+			continue
+		}
+		points = append(points, dto.AccountBalancePoint{Date: row.Date, Change: change, Balance: balance})
+	}
+	return &dto.AccountBalanceHistory{
+		AccountID:    account.ID,
+		Currency:     account.Currency,
+		StartBalance: start,
+		EndBalance:   balance,
+		Points:       points,
+	}, nil
+}
+
+// currentConverter returns a converter to the currency at today's rates.
+func (s *AccountService) currentConverter(to string) (*currency.Converter, error) {
+	return s.Rates.NewConverter(to, dto.RateModeCurrent, nil, "", "")
+}
+
+func validateAccount(account *dbmodel.Account) error {
+	account.Name = strings.TrimSpace(account.Name)
+	if account.Name == "" {
+		return errors.New("account name is required")
+	}
+	account.Currency = strings.ToUpper(strings.TrimSpace(account.Currency))
+	if account.Currency == "" {
+		return errors.New("account currency is required")
+	}
+	if err := money.ValidateCurrency(account.Currency); err != nil {
+		return err
+	}
+	return money.Validate(account.OpeningBalance, account.Currency)
+}
@@ -0,0 +1,201 @@
+package attachment
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"mindoh-service/common/utils"
+	"mindoh-service/internal/auth"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttachmentHandler handles HTTP requests for expense attachments
+type AttachmentHandler struct {
+	Service *AttachmentService
+}
+
+func NewAttachmentHandler(service *AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{Service: service}
+}
+
+// ownedExpense loads the expense from the :id path parameter and applies the
+// same ownership check as UpdateExpense. It writes the error response and
+// returns nil when the caller may not access the expense.
+func (h *AttachmentHandler) ownedExpense(c *gin.Context) *dbmodel.Expense {
+	authCtx := auth.GetAuthContext(c)
+	expense, err := h.Service.GetExpense(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		return nil
+	}
+	if authCtx.Role == auth.RoleUser && expense.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only access attachments of your own expenses"})
+		return nil
+	}
+	return expense
+}
+
+// expenseAttachment loads the attachment from the :attachment_id path
+// parameter, which must belong to the expense.
+func (h *AttachmentHandler) expenseAttachment(c *gin.Context, expense *dbmodel.Expense) *dbmodel.Attachment {
+	attachment, err := h.Service.GetAttachmentByID(utils.ParseUint(c.Param("attachment_id")))
+	if err != nil || attachment.ExpenseID != expense.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return nil
+	}
+	return attachment
+}
+
+// UploadAttachment godoc
+// @Summary Upload an attachment
+// @Description Attach a receipt or invoice (JPEG, PNG, GIF, WebP or PDF) to an expense. The type is detected from the file content.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} dto.AttachmentResponse "Attachment uploaded successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense not found"
+// @Failure 413 {object} map[string]interface{} "File too large"
+// @Failure 415 {object} map[string]interface{} "Unsupported file type"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	expense := h.ownedExpense(c)
+	if expense == nil {
+		return
+	}
+	// Leave room for the multipart envelope around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Service.MaxSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.Service.Upload(c.Request.Context(), expense, header.Filename, file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrFileTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrUnsupportedType):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrEmptyFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toAttachmentResponse(attachment))
+}
+
+// ListAttachments godoc
+// @Summary List attachments
+// @Description Get the attachments of an expense
+// @Tags attachments
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Success 200 {array} dto.AttachmentResponse "List of attachments"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id}/attachments [get]
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	expense := h.ownedExpense(c)
+	if expense == nil {
+		return
+	}
+	attachments, err := h.Service.ListAttachments(expense.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attachments"})
+		return
+	}
+	c.JSON(http.StatusOK, toAttachmentResponseList(attachments))
+}
+
+// DownloadAttachment godoc
+// @Summary Download an attachment
+// @Description Download the content of an expense attachment
+// @Tags attachments
+// @Produce application/octet-stream
+// @Param id path int true "Expense ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {file} file "Attachment content"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Attachment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	expense := h.ownedExpense(c)
+	if expense == nil {
+		return
+	}
+	attachment := h.expenseAttachment(c, expense)
+	if attachment == nil {
+		return
+	}
+	content, err := h.Service.Open(c.Request.Context(), attachment)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment content not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read attachment"})
+		return
+	}
+	defer content.Close()
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Delete an expense attachment and its stored content
+// @Tags attachments
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {object} map[string]interface{} "Attachment deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Attachment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	expense := h.ownedExpense(c)
+	if expense == nil {
+		return
+	}
+	attachment := h.expenseAttachment(c, expense)
+	if attachment == nil {
+		return
+	}
+	if err := h.Service.DeleteAttachment(c.Request.Context(), attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
@@ -0,0 +1,28 @@
+package attachment
+
+import (
+	"fmt"
+
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+)
+
+func toAttachmentResponse(a *dbmodel.Attachment) dto.AttachmentResponse {
+	return dto.AttachmentResponse{
+		ID:          a.ID,
+		ExpenseID:   a.ExpenseID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		DownloadURL: fmt.Sprintf("/api/expenses/%d/attachments/%d", a.ExpenseID, a.ID),
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+func toAttachmentResponseList(attachments []dbmodel.Attachment) []dto.AttachmentResponse {
+	result := make([]dto.AttachmentResponse, len(attachments))
+	for i := range attachments {
+		result[i] = toAttachmentResponse(&attachments[i])
+	}
+	return result
+}
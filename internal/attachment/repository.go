@@ -0,0 +1,39 @@
+package attachment
+
+import (
+	dbmodel "mindoh-service/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// AttachmentRepository handles DB operations for attachments
+type AttachmentRepository struct {
+	DB *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{DB: db}
+}
+
+func (r *AttachmentRepository) Create(attachment *dbmodel.Attachment) error {
+	return r.DB.Create(attachment).Error
+}
+
+func (r *AttachmentRepository) GetByID(id uint) (*dbmodel.Attachment, error) {
+	var attachment dbmodel.Attachment
+	err := r.DB.First(&attachment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) ListByExpense(expenseID uint) ([]dbmodel.Attachment, error) {
+	var attachments []dbmodel.Attachment
+	err := r.DB.Where("expense_id = ?", expenseID).Order("created_at asc, id asc").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *AttachmentRepository) Delete(id uint) error {
+	return r.DB.Delete(&dbmodel.Attachment{}, id).Error
+}
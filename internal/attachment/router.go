@@ -0,0 +1,20 @@
+package attachment
+
+import (
+	"mindoh-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAttachmentRoutes(r *gin.Engine, a auth.IAuthService, service *AttachmentService, resolveUser func(string) (uint, error)) {
+	handler := NewAttachmentHandler(service)
+
+	group := r.Group("/api/expenses/:id/attachments")
+	group.Use(a.AuthMiddleware(resolveUser))
+	{
+		group.POST("/", handler.UploadAttachment)
+		group.GET("/", handler.ListAttachments)
+		group.GET("/:attachment_id", handler.DownloadAttachment)
+		group.DELETE("/:attachment_id", handler.DeleteAttachment)
+	}
+}
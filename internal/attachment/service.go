@@ -0,0 +1,159 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/expense"
+	"mindoh-service/internal/storage"
+)
+
+var (
+	// ErrFileTooLarge is returned when an upload exceeds the configured size limit.
+	ErrFileTooLarge = errors.New("file is too large")
+	// ErrUnsupportedType is returned when the uploaded content is not an accepted type.
+	ErrUnsupportedType = errors.New("unsupported file type")
+	// ErrEmptyFile is returned when the uploaded file has no content.
+	ErrEmptyFile = errors.New("file is empty")
+)
+
+// allowedTypes maps the accepted content types, as sniffed from the file
+// content, to the extension used in the storage key.
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// AttachmentService handles business logic for expense attachments
+type AttachmentService struct {
+	Repo        *AttachmentRepository
+	ExpenseRepo *expense.ExpenseRepository
+	Storage     storage.Storage
+	MaxSize     int64 // bytes
+}
+
+func NewAttachmentService(repo *AttachmentRepository, expenseRepo *expense.ExpenseRepository, store storage.Storage, maxSize int64) *AttachmentService {
+	return &AttachmentService{Repo: repo, ExpenseRepo: expenseRepo, Storage: store, MaxSize: maxSize}
+}
+
+func (s *AttachmentService) GetExpense(id uint) (*dbmodel.Expense, error) {
+	return s.ExpenseRepo.GetByID(id)
+}
+
+func (s *AttachmentService) GetAttachmentByID(id uint) (*dbmodel.Attachment, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *AttachmentService) ListAttachments(expenseID uint) ([]dbmodel.Attachment, error) {
+	return s.Repo.ListByExpense(expenseID)
+}
+
+// Upload stores size bytes read from r as an attachment of the expense and
+// records its metadata. The content type is sniffed from the content instead
+// of trusting the client.
+func (s *AttachmentService) Upload(ctx context.Context, e *dbmodel.Expense, fileName string, r io.Reader, size int64) (*dbmodel.Attachment, error) {
+	if size > s.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrEmptyFile
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	key, err := newStorageKey(e.ID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Storage.Put(ctx, key, io.MultiReader(bytes.NewReader(head), r), size, contentType); err != nil {
+		return nil, err
+	}
+	attachment := &dbmodel.Attachment{
+		ExpenseID:   e.ID,
+		UserID:      e.UserID,
+		FileName:    cleanFileName(fileName, ext),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}
+	if err := s.Repo.Create(attachment); err != nil {
+		if delErr := s.Storage.Delete(ctx, key); delErr != nil {
+			slog.Warn("failed to remove orphaned attachment", "key", key, "error", delErr)
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Open returns the content of the attachment. The caller must close it.
+func (s *AttachmentService) Open(ctx context.Context, attachment *dbmodel.Attachment) (io.ReadCloser, error) {
+	return s.Storage.Get(ctx, attachment.StorageKey)
+}
+
+// DeleteAttachment removes the metadata and then the stored content. A failure
+// to remove the content is only logged, since the attachment is already gone
+// for the user.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, attachment *dbmodel.Attachment) error {
+	if err := s.Repo.Delete(attachment.ID); err != nil {
+		return err
+	}
+	if err := s.Storage.Delete(ctx, attachment.StorageKey); err != nil {
+		slog.Warn("failed to remove attachment content", "key", attachment.StorageKey, "error", err)
+	}
+	return nil
+}
+
+// newStorageKey returns a random, unguessable key below the expense's prefix.
+func newStorageKey(expenseID uint, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("expenses/%d/%s%s", expenseID, hex.EncodeToString(b), ext), nil
+}
+
+// cleanFileName keeps the base name of a client-supplied file name without
+// control characters, capped at 255 bytes. An empty name becomes
+// "attachment" plus ext.
+func cleanFileName(name, ext string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		return "attachment" + ext
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,241 @@
+package budget
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"mindoh-service/common/utils"
+	"mindoh-service/internal/auth"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BudgetHandler handles HTTP requests for budgets
+type BudgetHandler struct {
+	Service *BudgetService
+}
+
+func NewBudgetHandler(service *BudgetService) *BudgetHandler {
+	return &BudgetHandler{Service: service}
+}
+
+// CreateBudget godoc
+// @Summary Create a budget
+// @Description Create a monthly spending limit for an expense type, or overall when type is omitted
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param budget body dto.BudgetCreateRequest true "Budget details"
+// @Success 201 {object} dto.BudgetResponse "Budget created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 409 {object} map[string]interface{} "Budget for this type already exists"
+// @Security BearerAuth
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var req dto.BudgetCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own budgets"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.Currency == "" {
+		req.Currency = "VND"
+	}
+	budget := dbmodel.Budget{
+		UserID:     req.UserID,
+		Type:       strings.ToLower(strings.TrimSpace(req.Type)),
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		StartMonth: req.StartMonth,
+	}
+	if err := h.Service.CreateBudget(&budget); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A budget for this type already exists"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toBudgetResponse(&budget))
+}
+
+// ListBudgets godoc
+// @Summary List budgets
+// @Description Get budgets, overall budget first
+// @Tags budgets
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Success 200 {array} dto.BudgetResponse "List of budgets"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.BudgetFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own budgets"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	budgets, err := h.Service.ListBudgets(filter.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch budgets"})
+		return
+	}
+	c.JSON(http.StatusOK, toBudgetResponseList(budgets))
+}
+
+// Status godoc
+// @Summary Get budget status
+// @Description Get spent, remaining and percent used per budget for the given month and the preceding ones. Spending in other currencies is converted to the budget currency.
+// @Tags budgets
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param month query string false "Last month to report (YYYY-MM, default: current month)"
+// @Param periods query int false "Number of months to report, newest first (default: 1, max: 36)"
+// @Success 200 {array} dto.BudgetStatus "Budget status per period"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /budgets/status [get]
+func (h *BudgetHandler) Status(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.BudgetStatusFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own budgets"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	if filter.Month != "" {
+		if _, err := time.Parse(monthLayout, filter.Month); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format, expected YYYY-MM"})
+			return
+		}
+	}
+	status, err := h.Service.Status(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch budget status"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// UpdateBudget godoc
+// @Summary Update a budget
+// @Description Update the limit, currency or start month of a budget
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Param budget body dto.BudgetUpdateRequest true "Budget update details"
+// @Success 200 {object} dto.BudgetResponse "Budget updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Budget not found"
+// @Security BearerAuth
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	var req dto.BudgetUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	budget, err := h.Service.GetBudgetByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && budget.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own budgets"})
+		return
+	}
+
+	fields := map[string]interface{}{}
+	if req.Amount != nil {
+		budget.Amount = *req.Amount
+		fields["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		budget.Currency = *req.Currency
+		fields["currency"] = *req.Currency
+	}
+	if req.StartMonth != nil {
+		budget.StartMonth = *req.StartMonth
+		fields["start_month"] = *req.StartMonth
+	}
+	if len(fields) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+
+	if err := h.Service.UpdateBudgetFields(budget, fields); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toBudgetResponse(budget))
+}
+
+// DeleteBudget godoc
+// @Summary Delete a budget
+// @Description Delete a budget by ID
+// @Tags budgets
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Success 200 {object} map[string]interface{} "Budget deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Budget not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	budget, err := h.Service.GetBudgetByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && budget.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own budgets"})
+		return
+	}
+	if err := h.Service.DeleteBudget(budget.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete budget"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Budget deleted successfully"})
+}
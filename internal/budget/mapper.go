@@ -0,0 +1,25 @@
+package budget
+
+import (
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+)
+
+func toBudgetResponse(b *dbmodel.Budget) dto.BudgetResponse {
+	return dto.BudgetResponse{
+		ID:         b.ID,
+		UserID:     b.UserID,
+		Type:       b.Type,
+		Amount:     b.Amount,
+		Currency:   b.Currency,
+		StartMonth: b.StartMonth,
+	}
+}
+
+func toBudgetResponseList(budgets []dbmodel.Budget) []dto.BudgetResponse {
+	result := make([]dto.BudgetResponse, len(budgets))
+	for i := range budgets {
+		result[i] = toBudgetResponse(&budgets[i])
+	}
+	return result
+}
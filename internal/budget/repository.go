@@ -0,0 +1,48 @@
+package budget
+
+import (
+	dbmodel "mindoh-service/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// BudgetRepository handles DB operations for budgets
+type BudgetRepository struct {
+	DB *gorm.DB
+}
+
+func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{DB: db}
+}
+
+func (r *BudgetRepository) GetByID(id uint) (*dbmodel.Budget, error) {
+	var budget dbmodel.Budget
+	err := r.DB.First(&budget, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *BudgetRepository) Create(budget *dbmodel.Budget) error {
+	return r.DB.Create(budget).Error
+}
+
+func (r *BudgetRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.DB.Model(&dbmodel.Budget{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *BudgetRepository) Delete(id uint) error {
+	return r.DB.Delete(&dbmodel.Budget{}, id).Error
+}
+
+// ListByUser returns the user's budgets, overall budget first, then by type.
+func (r *BudgetRepository) ListByUser(userID uint) ([]dbmodel.Budget, error) {
+	var budgets []dbmodel.Budget
+	query := r.DB.Order("type asc")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Find(&budgets).Error
+	return budgets, err
+}
@@ -0,0 +1,21 @@
+package budget
+
+import (
+	"mindoh-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterBudgetRoutes(r *gin.Engine, a auth.IAuthService, service *BudgetService, resolveUser func(string) (uint, error)) {
+	handler := NewBudgetHandler(service)
+
+	group := r.Group("/api/budgets")
+	group.Use(a.AuthMiddleware(resolveUser))
+	{
+		group.POST("/", handler.CreateBudget)
+		group.GET("/", handler.ListBudgets)
+		group.GET("/status", handler.Status)
+		group.PUT("/:id", handler.UpdateBudget)
+		group.DELETE("/:id", handler.DeleteBudget)
+	}
+}
@@ -0,0 +1,151 @@
+package budget
+
+import (
+	"errors"
+	"time"
+
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/expense"
+	"mindoh-service/internal/money"
+)
+
+const monthLayout = "2006-01"
+
+// maxStatusPeriods bounds how many past months a single status request may cover.
+const maxStatusPeriods = 36
+
+// BudgetService handles business logic for budgets
+type BudgetService struct {
+	Repo           *BudgetRepository
+	ExpenseService *expense.ExpenseService
+}
+
+func NewBudgetService(repo *BudgetRepository, expenseService *expense.ExpenseService) *BudgetService {
+	return &BudgetService{Repo: repo, ExpenseService: expenseService}
+}
+
+func (s *BudgetService) CreateBudget(budget *dbmodel.Budget) error {
+	if err := validateBudget(budget); err != nil {
+		return err
+	}
+	return s.Repo.Create(budget)
+}
+
+// UpdateBudgetFields updates only the explicitly provided fields for a budget.
+// budget is the in-memory state with the changes applied (used for validation).
+func (s *BudgetService) UpdateBudgetFields(budget *dbmodel.Budget, fields map[string]interface{}) error {
+	if err := validateBudget(budget); err != nil {
+		return err
+	}
+	return s.Repo.UpdateFields(budget.ID, fields)
+}
+
+func (s *BudgetService) GetBudgetByID(id uint) (*dbmodel.Budget, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *BudgetService) ListBudgets(userID uint) ([]dbmodel.Budget, error) {
+	return s.Repo.ListByUser(userID)
+}
+
+func (s *BudgetService) DeleteBudget(id uint) error {
+	return s.Repo.Delete(id)
+}
+
+// Status reports spent/remaining/percent used for every budget of the user,
+// for filter.Periods months ending at filter.Month (newest first).
+// Spending is computed with ExpenseService.Summary, so amounts in other
+// currencies are converted to the budget currency the same way as the summary endpoint.
+func (s *BudgetService) Status(filter dto.BudgetStatusFilter) ([]dto.BudgetStatus, error) {
+	month := filter.Month
+	if month == "" {
+		month = time.Now().Format(monthLayout)
+	}
+	end, err := time.Parse(monthLayout, month)
+	if err != nil {
+		return nil, errors.New("invalid month format, expected YYYY-MM")
+	}
+	periods := filter.Periods
+	if periods < 1 {
+		periods = 1
+	}
+	if periods > maxStatusPeriods {
+		periods = maxStatusPeriods
+	}
+
+	budgets, err := s.Repo.ListByUser(filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// One summary per (month, currency) is enough for every budget sharing them.
+	summaries := make(map[string]*dto.ExpenseSummary)
+	result := make([]dto.BudgetStatus, 0, len(budgets))
+	for i := range budgets {
+		b := &budgets[i]
+		status := dto.BudgetStatus{Budget: toBudgetResponse(b), Periods: []dto.BudgetPeriodStatus{}}
+		for p := 0; p < periods; p++ {
+			start := end.AddDate(0, -p, 0)
+			m := start.Format(monthLayout)
+			if b.StartMonth != "" && m < b.StartMonth {
+				break
+			}
+			key := m + "|" + b.Currency
+			summary, ok := summaries[key]
+			if !ok {
+				summary, err = s.ExpenseService.Summary(dto.SummaryFilter{
+					UserID:           filter.UserID,
+					Kind:             string(dbmodel.ExpenseKindExpense),
+					OriginalCurrency: b.Currency,
+					From:             start.Format("2006-01-02"),
+					To:               start.AddDate(0, 1, -1).Format("2006-01-02"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				summaries[key] = summary
+			}
+			status.Periods = append(status.Periods, periodStatus(b, m, start, summary))
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+func periodStatus(b *dbmodel.Budget, month string, start time.Time, summary *dto.ExpenseSummary) dto.BudgetPeriodStatus {
+	// Expense amounts are stored negative; spending is reported as a positive number.
+	spent := summary.TotalExpense.Neg()
+	if b.Type != "" {
+		spent = summary.TotalByTypeExpense[b.Type].Neg()
+	}
+	percent := 0.0
+	if b.Amount.IsPositive() {
+		percent = spent.Div(b.Amount).Shift(2).Round(2).InexactFloat64()
+	}
+	return dto.BudgetPeriodStatus{
+		Month:       month,
+		From:        start.Format("2006-01-02"),
+		To:          start.AddDate(0, 1, -1).Format("2006-01-02"),
+		Limit:       b.Amount,
+		Spent:       spent,
+		Remaining:   b.Amount.Sub(spent),
+		PercentUsed: percent,
+		OverBudget:  spent.GreaterThan(b.Amount),
+	}
+}
+
+func validateBudget(budget *dbmodel.Budget) error {
+	if !budget.Amount.IsPositive() {
+		return errors.New("budget amount must be positive")
+	}
+	if err := money.Validate(budget.Amount, budget.Currency); err != nil {
+		return err
+	}
+	if budget.StartMonth != "" {
+		if _, err := time.Parse(monthLayout, budget.StartMonth); err != nil {
+			return errors.New("invalid start_month format, expected YYYY-MM")
+		}
+	}
+	return nil
+}
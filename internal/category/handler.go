@@ -0,0 +1,252 @@
+package category
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"mindoh-service/common/utils"
+	"mindoh-service/internal/auth"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	Service *CategoryService
+}
+
+func NewCategoryHandler(service *CategoryService) *CategoryHandler {
+	return &CategoryHandler{Service: service}
+}
+
+// canModify reports whether the caller may change the category: users only
+// their own custom categories, admins any category including the defaults.
+func canModify(authCtx auth.AuthContext, category *dbmodel.Category) bool {
+	if authCtx.Role != auth.RoleUser {
+		return true
+	}
+	return category.UserID != nil && *category.UserID == authCtx.UserID
+}
+
+// CreateCategory godoc
+// @Summary Create a custom category
+// @Description Create a custom expense or income category, optionally nested under a default or custom parent of the same kind
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body dto.CategoryCreateRequest true "Category details"
+// @Success 201 {object} dto.CategoryResponse "Category created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 409 {object} map[string]interface{} "Category name already exists"
+// @Security BearerAuth
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req dto.CategoryCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own categories"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	category := dbmodel.Category{
+		UserID:   &req.UserID,
+		ParentID: req.ParentID,
+		Name:     req.Name,
+		Kind:     dbmodel.ExpenseKind(req.Kind),
+		Icon:     req.Icon,
+		Color:    req.Color,
+	}
+	if err := h.Service.CreateCategory(&category); err != nil {
+		if errors.Is(err, ErrCategoryNameTaken) || errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": ErrCategoryNameTaken.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toCategoryResponse(&category))
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description Get the default categories plus the user's custom categories, as a flat list or as a tree
+// @Tags categories
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param kind query string false "Category kind (expense/income)"
+// @Param tree query bool false "Nest subcategories under their parent (default: false)"
+// @Success 200 {array} dto.CategoryResponse "List of categories"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /categories [get]
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.CategoryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own categories"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	categories, err := h.Service.ListCategories(filter.UserID, strings.ToLower(filter.Kind))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+	if filter.Tree {
+		c.JSON(http.StatusOK, toCategoryTree(categories))
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResponseList(categories))
+}
+
+// GetCategory godoc
+// @Summary Get a category
+// @Description Get a default category or one of the user's custom categories
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} dto.CategoryResponse "Category"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Category not found"
+// @Security BearerAuth
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	category, err := h.Service.GetCategoryByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && !Visible(category, authCtx.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own categories"})
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResponse(category))
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Rename, move, or restyle a custom category. Default categories can only be changed by admins.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param category body dto.CategoryUpdateRequest true "Category update details"
+// @Success 200 {object} dto.CategoryResponse "Category updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Category not found"
+// @Failure 409 {object} map[string]interface{} "Category name already exists"
+// @Security BearerAuth
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req dto.CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	category, err := h.Service.GetCategoryByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if !canModify(authCtx, category) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own categories"})
+		return
+	}
+
+	fields := map[string]interface{}{}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			category.ParentID = req.ParentID
+		}
+		fields["parent_id"] = category.ParentID
+	}
+	if req.Name != nil {
+		category.Name = strings.TrimSpace(*req.Name)
+		fields["name"] = category.Name
+	}
+	if req.Icon != nil {
+		category.Icon = *req.Icon
+		fields["icon"] = *req.Icon
+	}
+	if req.Color != nil {
+		category.Color = *req.Color
+		fields["color"] = *req.Color
+	}
+	if len(fields) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+
+	if err := h.Service.UpdateCategoryFields(category, fields); err != nil {
+		if errors.Is(err, ErrCategoryNameTaken) || errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": ErrCategoryNameTaken.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResponse(category))
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete a custom category without expenses or subcategories. Default categories can only be deleted by admins.
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]interface{} "Category deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Category not found"
+// @Failure 409 {object} map[string]interface{} "Category has expenses or subcategories"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	category, err := h.Service.GetCategoryByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if !canModify(authCtx, category) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own categories"})
+		return
+	}
+	if err := h.Service.DeleteCategory(category.ID); err != nil {
+		if errors.Is(err, ErrCategoryInUse) || errors.Is(err, ErrCategoryHasChildren) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
@@ -0,0 +1,62 @@
+package category
+
+import (
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+)
+
+func toCategoryResponse(c *dbmodel.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		ID:       c.ID,
+		UserID:   c.UserID,
+		ParentID: c.ParentID,
+		Name:     c.Name,
+		Kind:     string(c.Kind),
+		Icon:     c.Icon,
+		Color:    c.Color,
+		Default:  c.UserID == nil,
+	}
+}
+
+func toCategoryResponseList(categories []dbmodel.Category) []dto.CategoryResponse {
+	result := make([]dto.CategoryResponse, len(categories))
+	for i := range categories {
+		result[i] = toCategoryResponse(&categories[i])
+	}
+	return result
+}
+
+// toCategoryTree nests categories under their parents and returns the
+// top-level ones. Categories whose parent is not in the list are treated as
+// top-level. The input order is kept among siblings.
+func toCategoryTree(categories []dbmodel.Category) []dto.CategoryResponse {
+	children := make(map[uint][]dbmodel.Category)
+	present := make(map[uint]bool, len(categories))
+	for _, c := range categories {
+		present[c.ID] = true
+	}
+	var roots []dbmodel.Category
+	for _, c := range categories {
+		if c.ParentID != nil && present[*c.ParentID] && *c.ParentID != c.ID {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+	var build func(c *dbmodel.Category, depth int) dto.CategoryResponse
+	build = func(c *dbmodel.Category, depth int) dto.CategoryResponse {
+		resp := toCategoryResponse(c)
+		if depth >= len(categories) {
+			return resp
+		}
+		for i := range children[c.ID] {
+			resp.Children = append(resp.Children, build(&children[c.ID][i], depth+1))
+		}
+		return resp
+	}
+	result := make([]dto.CategoryResponse, len(roots))
+	for i := range roots {
+		result[i] = build(&roots[i], 0)
+	}
+	return result
+}
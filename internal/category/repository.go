@@ -0,0 +1,86 @@
+package category
+
+import (
+	"strings"
+
+	dbmodel "mindoh-service/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepository handles DB operations for categories
+type CategoryRepository struct {
+	DB *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{DB: db}
+}
+
+func (r *CategoryRepository) GetByID(id uint) (*dbmodel.Category, error) {
+	var category dbmodel.Category
+	err := r.DB.First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepository) Create(category *dbmodel.Category) error {
+	return r.DB.Create(category).Error
+}
+
+func (r *CategoryRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.DB.Model(&dbmodel.Category{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *CategoryRepository) Delete(id uint) error {
+	return r.DB.Delete(&dbmodel.Category{}, id).Error
+}
+
+// ListVisible returns the default categories plus the custom categories of
+// userID, optionally restricted to one kind.
+func (r *CategoryRepository) ListVisible(userID uint, kind string) ([]dbmodel.Category, error) {
+	var categories []dbmodel.Category
+	query := r.DB.Where("user_id IS NULL OR user_id = ?", userID).Order("kind asc, name asc")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Find(&categories).Error
+	return categories, err
+}
+
+// FindByName returns the category visible to userID whose name matches one of
+// names, ignoring case. Earlier names win, and the user's own categories win
+// over defaults. It returns nil when nothing matches.
+func (r *CategoryRepository) FindByName(userID uint, kind dbmodel.ExpenseKind, names ...string) (*dbmodel.Category, error) {
+	var categories []dbmodel.Category
+	err := r.DB.Where("(user_id IS NULL OR user_id = ?) AND kind = ? AND LOWER(name) IN ?", userID, kind, names).
+		Order("user_id IS NULL, id").
+		Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		for i := range categories {
+			if strings.EqualFold(categories[i].Name, name) {
+				return &categories[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// CountExpenses returns how many (non-deleted) expenses reference the category.
+func (r *CategoryRepository) CountExpenses(categoryID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&dbmodel.Expense{}).Where("category_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
+
+// CountChildren returns how many categories have categoryID as their parent.
+func (r *CategoryRepository) CountChildren(categoryID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&dbmodel.Category{}).Where("parent_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
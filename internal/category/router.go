@@ -0,0 +1,21 @@
+package category
+
+import (
+	"mindoh-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCategoryRoutes(r *gin.Engine, a auth.IAuthService, service *CategoryService, resolveUser func(string) (uint, error)) {
+	handler := NewCategoryHandler(service)
+
+	group := r.Group("/api/categories")
+	group.Use(a.AuthMiddleware(resolveUser))
+	{
+		group.POST("/", handler.CreateCategory)
+		group.GET("/", handler.ListCategories)
+		group.GET("/:id", handler.GetCategory)
+		group.PUT("/:id", handler.UpdateCategory)
+		group.DELETE("/:id", handler.DeleteCategory)
+	}
+}
@@ -0,0 +1,158 @@
+package category
+
+import (
+	"errors"
+	"strings"
+
+	dbmodel "mindoh-service/internal/db"
+)
+
+var (
+	// ErrCategoryInUse is returned when deleting a category that still has expenses.
+	ErrCategoryInUse = errors.New("category has expenses")
+	// ErrCategoryHasChildren is returned when deleting a category that still has subcategories.
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	// ErrCategoryNameTaken is returned when a category name clashes with another
+	// category of the same kind that the user can see.
+	ErrCategoryNameTaken = errors.New("a category with this name already exists")
+)
+
+// CategoryService handles business logic for categories
+type CategoryService struct {
+	Repo *CategoryRepository
+}
+
+func NewCategoryService(repo *CategoryRepository) *CategoryService {
+	return &CategoryService{Repo: repo}
+}
+
+// Visible reports whether the category can be used by userID: defaults are
+// shared, custom categories only by their owner.
+func Visible(c *dbmodel.Category, userID uint) bool {
+	return c.UserID == nil || *c.UserID == userID
+}
+
+// RootIDs maps every category ID to the ID of its top-level ancestor
+// (a top-level category maps to itself).
+func RootIDs(categories []dbmodel.Category) map[uint]uint {
+	parents := make(map[uint]*uint, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+	roots := make(map[uint]uint, len(categories))
+	for _, c := range categories {
+		id := c.ID
+		// The depth bound guards against a cycle in corrupted data.
+		for depth := 0; depth < len(categories); depth++ {
+			parent := parents[id]
+			if parent == nil {
+				break
+			}
+			if _, ok := parents[*parent]; !ok {
+				break
+			}
+			id = *parent
+		}
+		roots[c.ID] = id
+	}
+	return roots
+}
+
+func (s *CategoryService) CreateCategory(category *dbmodel.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if err := s.validate(category, ownerOf(category)); err != nil {
+		return err
+	}
+	return s.Repo.Create(category)
+}
+
+// UpdateCategoryFields updates only the explicitly provided fields for a category.
+// category is the in-memory state with the changes applied (used for validation).
+func (s *CategoryService) UpdateCategoryFields(category *dbmodel.Category, fields map[string]interface{}) error {
+	if err := s.validate(category, ownerOf(category)); err != nil {
+		return err
+	}
+	return s.Repo.UpdateFields(category.ID, fields)
+}
+
+func (s *CategoryService) GetCategoryByID(id uint) (*dbmodel.Category, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *CategoryService) ListCategories(userID uint, kind string) ([]dbmodel.Category, error) {
+	return s.Repo.ListVisible(userID, kind)
+}
+
+// DeleteCategory deletes a category that has neither expenses nor subcategories.
+func (s *CategoryService) DeleteCategory(id uint) error {
+	count, err := s.Repo.CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryHasChildren
+	}
+	count, err = s.Repo.CountExpenses(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryInUse
+	}
+	return s.Repo.Delete(id)
+}
+
+func ownerOf(category *dbmodel.Category) uint {
+	if category.UserID == nil {
+		return 0
+	}
+	return *category.UserID
+}
+
+// validate checks the name, the kind and the parent of a category. The parent
+// must be visible to the owner, have the same kind and not be the category
+// itself or one of its descendants.
+func (s *CategoryService) validate(category *dbmodel.Category, owner uint) error {
+	if category.Name == "" {
+		return errors.New("name is required")
+	}
+	if category.Kind != dbmodel.ExpenseKindExpense && category.Kind != dbmodel.ExpenseKindIncome {
+		return errors.New("kind must be expense or income")
+	}
+	existing, err := s.Repo.FindByName(owner, category.Kind, strings.ToLower(category.Name))
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != category.ID {
+		return ErrCategoryNameTaken
+	}
+	if category.ParentID == nil {
+		return nil
+	}
+	parent, err := s.Repo.GetByID(*category.ParentID)
+	if err != nil || !Visible(parent, owner) {
+		return errors.New("parent category not found")
+	}
+	if parent.Kind != category.Kind {
+		return errors.New("parent category must have the same kind")
+	}
+	if category.ID == 0 {
+		return nil
+	}
+	all, err := s.Repo.ListVisible(owner, string(category.Kind))
+	if err != nil {
+		return err
+	}
+	parents := make(map[uint]*uint, len(all))
+	for _, c := range all {
+		parents[c.ID] = c.ParentID
+	}
+	id := &parent.ID
+	for depth := 0; id != nil && depth <= len(all); depth++ {
+		if *id == category.ID {
+			return errors.New("a category cannot be nested under itself")
+		}
+		id = parents[*id]
+	}
+	return nil
+}
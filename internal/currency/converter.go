@@ -0,0 +1,72 @@
+package currency
+
+import (
+	"time"
+
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+// Converter expresses amounts in one target currency using VND-based rates.
+// A manual override of the pair comes first, then the rate snapshots and
+// finally the live rates. Each converted amount is rounded to the minor unit
+// of the target currency before it is added to a total, so totals are exact
+// sums of what is shown.
+type Converter struct {
+	Target  string
+	rates   map[string]float64 // live rates
+	history *HistoricalRates
+	date    string // in current mode, the day every amount is converted at
+}
+
+// NewConverter returns a converter to target. In historical mode it loads the
+// snapshots and overrides of the currencies for dates within [from, to], and
+// each amount is converted at the rates of its own date. In current mode
+// every amount is converted at today's overrides and live rates.
+func (h *RateHistory) NewConverter(target, mode string, currencies []string, from, to string) (*Converter, error) {
+	c := &Converter{Target: target, rates: h.Live.GetRates()}
+	if mode == dto.RateModeCurrent {
+		c.date = time.Now().Format("2006-01-02")
+		overrides, err := h.Repo.ListOverridesFor(target, c.date)
+		if err != nil {
+			return nil, err
+		}
+		c.history = &HistoricalRates{overrides: overrides}
+		return c, nil
+	}
+	history, err := h.Load(append(currencies, target), from, to)
+	if err != nil {
+		return nil, err
+	}
+	c.history = history
+	return c, nil
+}
+
+// Convert expresses amount (in from) in the target currency at the rates of
+// date. Currencies without a snapshot are converted at the live rate.
+func (c *Converter) Convert(amount decimal.Decimal, from, date string) decimal.Decimal {
+	if from == c.Target {
+		return amount
+	}
+	if c.date != "" {
+		date = c.date
+	}
+	if rate, ok := c.history.Override(from, c.Target, date); ok {
+		return money.Round(amount.Mul(rate), c.Target)
+	}
+	return money.Convert(amount, c.rate(from, date), c.rate(c.Target, date), c.Target)
+}
+
+// HasLiveRate reports whether a live rate is known for code.
+func (c *Converter) HasLiveRate(code string) bool {
+	return c.rates[code] > 0
+}
+
+func (c *Converter) rate(code, date string) decimal.Decimal {
+	if rate, ok := c.history.At(code, date); ok {
+		return rate
+	}
+	return decimal.NewFromFloat(c.rates[code])
+}
@@ -1,107 +1,204 @@
 package currency
 
 import (
-	"encoding/json"
-	"io"
+	"context"
+	"errors"
 	"log/slog"
-	"net/http"
 	"sync"
 	"time"
+
+	"mindoh-service/internal/money"
+
+	"golang.org/x/sync/singleflight"
 )
 
-// ExchangeRateService handles fetching and caching exchange rates
+// defaultSource is reported while only the built-in default rates are known.
+const defaultSource = "default"
+
+// ExchangeRateService caches the exchange rates and refreshes them in the
+// background. Readers are never blocked on the network: once the rates are
+// older than cacheTTL they are still served, marked stale, while a refresh runs.
 type ExchangeRateService struct {
-	rates      map[string]float64
-	lastUpdate time.Time
-	mu         sync.RWMutex
-	cacheTTL   time.Duration
-}
+	providers   []RateProvider
+	rates       map[string]float64
+	date        string // day the rates apply to, as reported by the provider
+	source      string // provider the rates came from
+	lastUpdate  time.Time
+	lastAttempt time.Time
+	lastError   string
+	mu          sync.RWMutex
+	cacheTTL    time.Duration
+	refreshes   singleflight.Group
 
-// ExchangeRateAPIResponse represents the response from fawazahmed0 currency API
-type ExchangeRateAPIResponse struct {
-	Date  string             `json:"date"`
-	Base  string             `json:"base"`
-	Rates map[string]float64 `json:"rates"`
-	VND   map[string]float64 `json:"vnd"`
+	// Retries is the number of extra attempts after a refresh fails; the wait
+	// before each one starts at Backoff and doubles.
+	Retries int
+	Backoff time.Duration
 }
 
-var globalExchangeRateService *ExchangeRateService
-var once sync.Once
+// RateStatus describes the cached rates.
+type RateStatus struct {
+	Date      string
+	Source    string
+	UpdatedAt time.Time // zero while only the defaults are known
+	Age       time.Duration
+	Stale     bool
+	LastError string // error of the last failed refresh, empty after a success
+}
 
-// GetExchangeRateService returns the singleton instance
-func GetExchangeRateService() *ExchangeRateService {
-	once.Do(func() {
-		globalExchangeRateService = &ExchangeRateService{
-			rates:      make(map[string]float64),
-			cacheTTL:   6 * time.Hour,
-			lastUpdate: time.Time{},
-		}
-		globalExchangeRateService.rates = map[string]float64{
+// NewExchangeRateService returns a service that asks the providers in order
+// until one succeeds, treating the rates as fresh for cacheTTL. Until the
+// first fetch succeeds it serves built-in defaults. Call Start to schedule the
+// refreshes.
+func NewExchangeRateService(providers []RateProvider, cacheTTL time.Duration) *ExchangeRateService {
+	return &ExchangeRateService{
+		providers: providers,
+		rates: map[string]float64{
 			"VND": 1,
 			"USD": 25000,
 			"EUR": 27000,
+		},
+		source:   defaultSource,
+		cacheTTL: cacheTTL,
+		Retries:  3,
+		Backoff:  5 * time.Second,
+	}
+}
+
+// Start refreshes the rates once immediately and then every cacheTTL, in the
+// background.
+func (e *ExchangeRateService) Start() {
+	go func() {
+		e.RefreshRates()
+		ticker := time.NewTicker(e.cacheTTL)
+		defer ticker.Stop()
+		for range ticker.C {
+			e.RefreshRates()
 		}
-		go globalExchangeRateService.fetchRates()
-	})
-	return globalExchangeRateService
+	}()
 }
 
-func (e *ExchangeRateService) fetchRates() {
-	primaryURL := "https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies/vnd.json"
-	fallbackURL := "https://latest.currency-api.pages.dev/v1/currencies/vnd.json"
+// RefreshRates fetches the rates, retrying with backoff, and waits for the
+// result. Concurrent calls share a single refresh.
+func (e *ExchangeRateService) RefreshRates() error {
+	_, err, _ := e.refreshes.Do("refresh", func() (interface{}, error) {
+		return nil, e.refreshWithRetry()
+	})
+	return err
+}
 
-	slog.Info("fetching exchange rates", "url", primaryURL)
-	resp, err := http.Get(primaryURL)
-	if err != nil {
-		slog.Warn("primary exchange rate URL failed, trying fallback", "error", err, "url", fallbackURL)
-		resp, err = http.Get(fallbackURL)
-		if err != nil {
-			slog.Error("failed to fetch exchange rates", "error", err)
-			return
+func (e *ExchangeRateService) refreshWithRetry() error {
+	wait := e.Backoff
+	var err error
+	for attempt := 0; attempt <= e.Retries; attempt++ {
+		if attempt > 0 {
+			slog.Warn("retrying exchange rate refresh", "attempt", attempt+1, "wait", wait, "error", err)
+			time.Sleep(wait)
+			wait *= 2
+		}
+		if err = e.fetchRates(); err == nil {
+			return nil
 		}
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("exchange rate API returned non-200", "status", resp.StatusCode)
-		return
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		slog.Error("failed reading exchange rate body", "error", err)
-		return
-	}
-	var apiResp ExchangeRateAPIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		slog.Error("failed parsing exchange rate JSON", "error", err)
-		return
-	}
-	newRates := make(map[string]float64)
-	newRates["VND"] = 1
-	if apiResp.VND != nil {
-		if usdRate, ok := apiResp.VND["usd"]; ok && usdRate > 0 {
-			newRates["USD"] = 1 / usdRate
+	slog.Error("giving up refreshing exchange rates", "attempts", e.Retries+1, "error", err)
+	return err
+}
+
+func (e *ExchangeRateService) fetchRates() error {
+	e.mu.Lock()
+	e.lastAttempt = time.Now()
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var errs []error
+	for _, provider := range e.providers {
+		slog.Info("fetching exchange rates", "provider", provider.Name())
+		date, newRates, err := provider.Fetch(ctx)
+		if err != nil {
+			slog.Warn("exchange rate provider failed", "provider", provider.Name(), "error", err)
+			errs = append(errs, err)
+			continue
 		}
-		if eurRate, ok := apiResp.VND["eur"]; ok && eurRate > 0 {
-			newRates["EUR"] = 1 / eurRate
+		if date == "" {
+			date = time.Now().Format("2006-01-02")
 		}
+		newRates = knownRates(newRates)
+		e.mu.Lock()
+		e.rates = newRates
+		e.date = date
+		e.source = provider.Name()
+		e.lastUpdate = time.Now()
+		e.lastError = ""
+		e.mu.Unlock()
+		slog.Info("exchange rates updated", "provider", provider.Name(), "date", date, "currencies", len(newRates), "USD_to_VND", newRates["USD"], "EUR_to_VND", newRates["EUR"])
+		return nil
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		err = errors.New("no exchange rate provider configured")
 	}
 	e.mu.Lock()
-	e.rates = newRates
-	e.lastUpdate = time.Now()
+	e.lastError = err.Error()
 	e.mu.Unlock()
-	slog.Info("exchange rates updated", "USD_to_VND", newRates["USD"], "EUR_to_VND", newRates["EUR"])
+	return err
 }
 
-// GetRates returns a copy of current rates (to VND)
+// GetRates returns a copy of current rates (to VND). It never waits for the
+// network: stale rates are returned as they are and a refresh is started in
+// the background.
 func (e *ExchangeRateService) GetRates() map[string]float64 {
+	e.refreshIfStale()
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.copyRates()
+}
+
+// refreshIfStale starts a background refresh when the rates are stale and no
+// attempt was made within the last Backoff, so failing providers are not
+// hammered by every request.
+func (e *ExchangeRateService) refreshIfStale() {
 	e.mu.RLock()
-	expired := time.Since(e.lastUpdate) > e.cacheTTL
+	start := e.stale() && time.Since(e.lastAttempt) > e.Backoff
 	e.mu.RUnlock()
-	if expired {
-		e.fetchRates()
+	if start {
+		go e.RefreshRates()
+	}
+}
+
+// Status describes the cached rates without refreshing them.
+func (e *ExchangeRateService) Status() RateStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	status := RateStatus{
+		Date:      e.date,
+		Source:    e.source,
+		UpdatedAt: e.lastUpdate,
+		Stale:     e.stale(),
+		LastError: e.lastError,
 	}
+	if !e.lastUpdate.IsZero() {
+		status.Age = time.Since(e.lastUpdate)
+	}
+	return status
+}
+
+// Snapshot returns a copy of the current rates (to VND) and the day they apply
+// to. ok is false while no rates have been fetched yet and only the built-in
+// defaults are known.
+func (e *ExchangeRateService) Snapshot() (date string, rates map[string]float64, ok bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	return e.date, e.copyRates(), !e.lastUpdate.IsZero()
+}
+
+// stale must be called with e.mu held.
+func (e *ExchangeRateService) stale() bool {
+	return e.lastUpdate.IsZero() || time.Since(e.lastUpdate) > e.cacheTTL
+}
+
+// copyRates must be called with e.mu held.
+func (e *ExchangeRateService) copyRates() map[string]float64 {
 	out := make(map[string]float64)
 	for k, v := range e.rates {
 		out[k] = v
@@ -109,5 +206,14 @@ func (e *ExchangeRateService) GetRates() map[string]float64 {
 	return out
 }
 
-// RefreshRates forces refresh
-func (e *ExchangeRateService) RefreshRates() { e.fetchRates() }
+// knownRates drops the rates of codes that are not ISO 4217 currencies, such
+// as the crypto and metal quotes some sources mix in.
+func knownRates(rates map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(rates))
+	for code, rate := range rates {
+		if _, ok := money.Lookup(code); ok {
+			out[code] = rate
+		}
+	}
+	return out
+}
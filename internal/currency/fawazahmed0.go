@@ -0,0 +1,79 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Fawazahmed0Provider fetches rates from the free fawazahmed0 currency API,
+// trying the jsDelivr CDN first and the Cloudflare Pages mirror second.
+type Fawazahmed0Provider struct {
+	URLs []string
+}
+
+// ExchangeRateAPIResponse represents the response from fawazahmed0 currency API
+type ExchangeRateAPIResponse struct {
+	Date  string             `json:"date"`
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+	VND   map[string]float64 `json:"vnd"`
+}
+
+func NewFawazahmed0Provider() *Fawazahmed0Provider {
+	return &Fawazahmed0Provider{URLs: []string{
+		"https://cdn.jsdelivr.net/npm/@fawazahmed0/currency-api@latest/v1/currencies/vnd.json",
+		"https://latest.currency-api.pages.dev/v1/currencies/vnd.json",
+	}}
+}
+
+func (p *Fawazahmed0Provider) Name() string { return "fawazahmed0" }
+
+func (p *Fawazahmed0Provider) Fetch(ctx context.Context) (string, map[string]float64, error) {
+	var err error
+	for i, url := range p.URLs {
+		if i > 0 {
+			slog.Warn("exchange rate URL failed, trying fallback", "error", err, "url", url)
+		}
+		var apiResp *ExchangeRateAPIResponse
+		if apiResp, err = p.fetch(ctx, url); err == nil {
+			return apiResp.Date, vndRates(apiResp.VND), nil
+		}
+	}
+	return "", nil, err
+}
+
+func (p *Fawazahmed0Provider) fetch(ctx context.Context, url string) (*ExchangeRateAPIResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange rate API returned status %d", resp.StatusCode)
+	}
+	var apiResp ExchangeRateAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("parse exchange rate JSON: %w", err)
+	}
+	return &apiResp, nil
+}
+
+// vndRates turns the API's units of each currency per VND into the value of
+// one unit in VND, keeping the available currencies.
+func vndRates(perVND map[string]float64) map[string]float64 {
+	rates := map[string]float64{"VND": 1}
+	for _, code := range AvailableCurrencies {
+		if rate, ok := perVND[strings.ToLower(code)]; ok && rate > 0 {
+			rates[code] = 1 / rate
+		}
+	}
+	return rates
+}
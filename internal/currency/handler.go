@@ -1,36 +1,255 @@
 package currency
 
 import (
+	"errors"
 	"net/http"
+	"time"
+
+	"mindoh-service/common/utils"
+	"mindoh-service/internal/auth"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/money"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
-type CurrencyHandler struct{}
+type CurrencyHandler struct {
+	Service *CurrencyService
+}
 
-func NewCurrencyHandler() *CurrencyHandler { return &CurrencyHandler{} }
+func NewCurrencyHandler(service *CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{Service: service}
+}
 
 // GetExchangeRates godoc
 // @Summary Get exchange rates
-// @Description Get exchange rates (base VND)
+// @Description Get the cached exchange rates (base VND) with the provider they came from and their age. Rates older than the refresh interval are marked stale and served while a refresh runs in the background.
 // @Tags currency
 // @Produce json
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} dto.ExchangeRatesResponse
 // @Security BearerAuth
 // @Router /currency/exchange-rates [get]
 func (h *CurrencyHandler) GetExchangeRates(c *gin.Context) {
-	rates := GetExchangeRateService().GetRates()
-	c.JSON(http.StatusOK, gin.H{"base_currency": "VND", "rates": rates})
+	rates := h.Service.Rates.GetRates()
+	c.JSON(http.StatusOK, toExchangeRatesResponse(rates, h.Service.Rates.Status()))
 }
 
 // GetAvailableCurrencies godoc
 // @Summary Get available currencies
-// @Description List supported currency codes
+// @Description List the ISO 4217 currencies with their name, symbol and minor units. The caller's favorite currencies come first, in their order, then the others by code.
 // @Tags currency
 // @Produce json
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} map[string][]dto.CurrencyResponse
+// @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
 // @Router /currency/currencies [get]
 func (h *CurrencyHandler) GetAvailableCurrencies(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"currencies": AvailableCurrencies})
+	authCtx := auth.GetAuthContext(c)
+	currencies, err := h.Service.ListCurrencies(authCtx.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch currencies"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"currencies": currencies})
+}
+
+// GetFavoriteCurrencies godoc
+// @Summary Get favorite currencies
+// @Description List the codes of the caller's favorite currencies, in their order
+// @Tags currency
+// @Produce json
+// @Success 200 {object} map[string][]string
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /currency/favorites [get]
+func (h *CurrencyHandler) GetFavoriteCurrencies(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	codes, err := h.Service.Favorites.List(authCtx.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorite currencies"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"currencies": codes})
+}
+
+// SetFavoriteCurrencies godoc
+// @Summary Set favorite currencies
+// @Description Replace the caller's favorite currencies (at most 20). They are listed first by /currency/currencies, in the given order.
+// @Tags currency
+// @Accept json
+// @Produce json
+// @Param favorites body dto.FavoriteCurrenciesRequest true "Currency codes"
+// @Success 200 {object} map[string][]string
+// @Failure 400 {object} map[string]interface{} "Invalid request or unknown currency"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /currency/favorites [put]
+func (h *CurrencyHandler) SetFavoriteCurrencies(c *gin.Context) {
+	var req dto.FavoriteCurrenciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	codes, err := h.Service.SetFavorites(authCtx.UserID, req.Currencies)
+	if errors.Is(err, money.ErrInvalidCurrency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save favorite currencies"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"currencies": codes})
+}
+
+// Convert godoc
+// @Summary Convert an amount
+// @Description Convert an amount between two currencies at the rates of a date, e.g. 120 USD in VND on 2025-03-02. A manual override of the pair covering the date takes precedence; otherwise the stored daily snapshot of the date (or the nearest earlier one) is used, falling back to the live rate for a currency without snapshots. The result is rounded to the minor unit of the target currency.
+// @Tags currency
+// @Produce json
+// @Param amount query number true "Amount in the from currency"
+// @Param from query string true "Currency of the amount"
+// @Param to query string true "Currency to convert to"
+// @Param date query string false "Date of the rates, YYYY-MM-DD (default: today)"
+// @Success 200 {object} dto.ConvertResponse
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters, unknown currency or no rate"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /currency/convert [get]
+func (h *CurrencyHandler) Convert(c *gin.Context) {
+	var query dto.ConvertQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	amount, err := decimal.NewFromString(query.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
+		return
+	}
+	if query.Date == "" {
+		query.Date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", query.Date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+		return
+	}
+	resp, err := h.Service.Convert(amount, query.From, query.To, query.Date)
+	if errors.Is(err, money.ErrInvalidCurrency) || errors.Is(err, ErrNoRate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert amount"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListRateOverrides godoc
+// @Summary List exchange rate overrides
+// @Description List the manual exchange rate overrides, newest first (admin only)
+// @Tags currency
+// @Produce json
+// @Param currency query string false "Only overrides involving this currency"
+// @Success 200 {array} dto.RateOverrideResponse
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/currency/overrides [get]
+func (h *CurrencyHandler) ListRateOverrides(c *gin.Context) {
+	overrides, err := h.Service.ListOverrides(c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rate overrides"})
+		return
+	}
+	c.JSON(http.StatusOK, toRateOverrideResponseList(overrides))
+}
+
+// CreateRateOverride godoc
+// @Summary Create an exchange rate override
+// @Description Set a manual rate for a currency pair over a date range, such as a bank's actual card rate (admin only). It takes precedence over provider rates in both directions of the pair, in conversions and in historical summaries; when overrides overlap, the newest wins.
+// @Tags currency
+// @Accept json
+// @Produce json
+// @Param override body dto.RateOverrideRequest true "Override details"
+// @Success 201 {object} dto.RateOverrideResponse
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Security BearerAuth
+// @Router /admin/currency/overrides [post]
+func (h *CurrencyHandler) CreateRateOverride(c *gin.Context) {
+	var req dto.RateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	override := dbmodel.ExchangeRateOverride{}
+	applyRateOverride(&override, &req)
+	if err := h.Service.SaveOverride(&override); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toRateOverrideResponse(&override))
+}
+
+// UpdateRateOverride godoc
+// @Summary Replace an exchange rate override
+// @Description Replace the pair, date range, rate and note of an override (admin only)
+// @Tags currency
+// @Accept json
+// @Produce json
+// @Param id path int true "Override ID"
+// @Param override body dto.RateOverrideRequest true "Override details"
+// @Success 200 {object} dto.RateOverrideResponse
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Override not found"
+// @Security BearerAuth
+// @Router /admin/currency/overrides/{id} [put]
+func (h *CurrencyHandler) UpdateRateOverride(c *gin.Context) {
+	var req dto.RateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	override, err := h.Service.GetOverride(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rate override not found"})
+		return
+	}
+	applyRateOverride(override, &req)
+	if err := h.Service.SaveOverride(override); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toRateOverrideResponse(override))
+}
+
+// DeleteRateOverride godoc
+// @Summary Delete an exchange rate override
+// @Description Delete an override; the provider rates apply again (admin only)
+// @Tags currency
+// @Produce json
+// @Param id path int true "Override ID"
+// @Success 200 {object} map[string]interface{} "Rate override deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Override not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/currency/overrides/{id} [delete]
+func (h *CurrencyHandler) DeleteRateOverride(c *gin.Context) {
+	override, err := h.Service.GetOverride(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rate override not found"})
+		return
+	}
+	if err := h.Service.DeleteOverride(override.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rate override"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rate override deleted successfully"})
 }
@@ -0,0 +1,110 @@
+package currency
+
+import (
+	"sort"
+
+	dbmodel "mindoh-service/internal/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateScale is the number of decimals stored for a rate.
+const rateScale = 10
+
+// ratioScale is the number of decimals kept when dividing one rate by
+// another, enough to convert large VND amounts into a strong currency.
+const ratioScale = 20
+
+// RateHistory records the live exchange rates as daily snapshots and looks up
+// the rates of past dates from them.
+type RateHistory struct {
+	Repo *RateRepository
+	Live *ExchangeRateService
+}
+
+func NewRateHistory(repo *RateRepository, live *ExchangeRateService) *RateHistory {
+	return &RateHistory{Repo: repo, Live: live}
+}
+
+// Record stores the live rates as the snapshot of the day they apply to.
+// Nothing is stored until rates have been fetched.
+func (h *RateHistory) Record() (date string, err error) {
+	date, rates, ok := h.Live.Snapshot()
+	if !ok {
+		return "", nil
+	}
+	snapshot := make([]dbmodel.ExchangeRate, 0, len(rates))
+	for code, rate := range rates {
+		if rate <= 0 {
+			continue
+		}
+		snapshot = append(snapshot, dbmodel.ExchangeRate{
+			Currency: code,
+			Date:     date,
+			Rate:     decimal.NewFromFloat(rate).Round(rateScale),
+		})
+	}
+	return date, h.Repo.Save(snapshot)
+}
+
+// Load returns the snapshots and overrides needed to convert between the
+// currencies on dates within [from, to]. Empty bounds are open.
+func (h *RateHistory) Load(currencies []string, from, to string) (*HistoricalRates, error) {
+	rows, err := h.Repo.ListRange(currencies, from, to)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := h.Repo.ListOverridesInRange(currencies, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byCurrency := make(map[string][]dbmodel.ExchangeRate)
+	for _, row := range rows {
+		byCurrency[row.Currency] = append(byCurrency[row.Currency], row)
+	}
+	for _, snapshots := range byCurrency {
+		sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Date < snapshots[j].Date })
+	}
+	return &HistoricalRates{byCurrency: byCurrency, overrides: overrides}, nil
+}
+
+// HistoricalRates holds daily snapshots of some currencies and the manual
+// overrides between them.
+type HistoricalRates struct {
+	byCurrency map[string][]dbmodel.ExchangeRate // sorted by date
+	overrides  []dbmodel.ExchangeRateOverride    // newest first
+}
+
+// At returns the rate (to VND) of currency on date: the snapshot of that day,
+// else of the nearest earlier day. A date before the first snapshot gets the
+// first snapshot. ok is false when there is no snapshot of the currency.
+func (r *HistoricalRates) At(currency, date string) (rate decimal.Decimal, ok bool) {
+	snapshots := r.byCurrency[currency]
+	if len(snapshots) == 0 {
+		return decimal.Zero, false
+	}
+	// Index of the first snapshot after date.
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].Date > date })
+	if i == 0 {
+		return snapshots[0].Rate, true
+	}
+	return snapshots[i-1].Rate, true
+}
+
+// Override returns the units of to per unit of from on date set by a manual
+// override of the pair, in either direction. The newest override covering
+// date wins. ok is false when none does.
+func (r *HistoricalRates) Override(from, to, date string) (rate decimal.Decimal, ok bool) {
+	for _, o := range r.overrides {
+		if date < o.StartDate || date > o.EndDate {
+			continue
+		}
+		if o.FromCurrency == from && o.ToCurrency == to {
+			return o.Rate, true
+		}
+		if o.FromCurrency == to && o.ToCurrency == from {
+			return decimal.NewFromInt(1).DivRound(o.Rate, ratioScale), true
+		}
+	}
+	return decimal.Zero, false
+}
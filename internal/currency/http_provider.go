@@ -0,0 +1,114 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HTTPProvider fetches rates from any JSON endpoint that quotes currencies
+// against a base, such as a local mock server:
+//
+//	{"base": "USD", "date": "2024-06-01", "rates": {"VND": 25400, "EUR": 0.92}}
+//
+// Each rate is the number of units of the currency per unit of the base. The
+// fields are found by dotted path, so nested responses work too.
+type HTTPProvider struct {
+	URL        string
+	Base       string // used when the response has no base field
+	RatesField string
+	DateField  string
+	BaseField  string
+}
+
+// NewHTTPProvider returns a provider for url. Empty fields default to rates,
+// date and base, and an empty base to VND.
+func NewHTTPProvider(url, base, ratesField, dateField, baseField string) (*HTTPProvider, error) {
+	if url == "" {
+		return nil, errors.New("http exchange rate provider needs a url")
+	}
+	p := &HTTPProvider{URL: url, Base: base, RatesField: ratesField, DateField: dateField, BaseField: baseField}
+	if p.Base == "" {
+		p.Base = "VND"
+	}
+	if p.RatesField == "" {
+		p.RatesField = "rates"
+	}
+	if p.DateField == "" {
+		p.DateField = "date"
+	}
+	if p.BaseField == "" {
+		p.BaseField = "base"
+	}
+	return p, nil
+}
+
+func (p *HTTPProvider) Name() string { return "http" }
+
+func (p *HTTPProvider) Fetch(ctx context.Context) (string, map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("exchange rate endpoint returned status %d", resp.StatusCode)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", nil, fmt.Errorf("parse exchange rate JSON: %w", err)
+	}
+
+	quoted, ok := lookupField(body, p.RatesField).(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("exchange rate response has no %q object", p.RatesField)
+	}
+	base := p.Base
+	if b, ok := lookupField(body, p.BaseField).(string); ok && b != "" {
+		base = b
+	}
+	base = strings.ToUpper(base)
+	perBase := map[string]float64{base: 1}
+	for code, v := range quoted {
+		if rate, ok := v.(float64); ok && rate > 0 {
+			perBase[strings.ToUpper(code)] = rate
+		}
+	}
+	vndPerBase, ok := perBase["VND"]
+	if !ok {
+		return "", nil, fmt.Errorf("exchange rate response has no VND rate against %s", base)
+	}
+	// One unit of a currency is worth 1/rate units of the base, i.e.
+	// vndPerBase/rate VND.
+	rates := make(map[string]float64, len(perBase))
+	for code, rate := range perBase {
+		rates[code] = vndPerBase / rate
+	}
+	date, _ := lookupField(body, p.DateField).(string)
+	if len(date) > 10 {
+		date = date[:10] // drop the time of an RFC 3339 timestamp
+	}
+	return date, rates, nil
+}
+
+// lookupField returns the value at the dotted path in a decoded JSON object,
+// or nil when there is none.
+func lookupField(obj map[string]interface{}, path string) interface{} {
+	var value interface{} = obj
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
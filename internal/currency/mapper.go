@@ -0,0 +1,67 @@
+package currency
+
+import (
+	"time"
+
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/money"
+)
+
+func toCurrencyResponse(c money.Currency, favorite bool) dto.CurrencyResponse {
+	return dto.CurrencyResponse{
+		Code:       c.Code,
+		Name:       c.Name,
+		Symbol:     c.Symbol,
+		MinorUnits: c.MinorUnits,
+		Favorite:   favorite,
+	}
+}
+
+func toExchangeRatesResponse(rates map[string]float64, status RateStatus) dto.ExchangeRatesResponse {
+	resp := dto.ExchangeRatesResponse{
+		BaseCurrency: "VND",
+		Rates:        rates,
+		Date:         status.Date,
+		Source:       status.Source,
+		AgeSeconds:   int64(status.Age / time.Second),
+		Stale:        status.Stale,
+		LastError:    status.LastError,
+	}
+	if !status.UpdatedAt.IsZero() {
+		updatedAt := status.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+func toRateOverrideResponse(o *dbmodel.ExchangeRateOverride) dto.RateOverrideResponse {
+	return dto.RateOverrideResponse{
+		ID:           o.ID,
+		FromCurrency: o.FromCurrency,
+		ToCurrency:   o.ToCurrency,
+		StartDate:    o.StartDate,
+		EndDate:      o.EndDate,
+		Rate:         o.Rate,
+		Note:         o.Note,
+		UpdatedAt:    o.UpdatedAt,
+	}
+}
+
+func toRateOverrideResponseList(overrides []dbmodel.ExchangeRateOverride) []dto.RateOverrideResponse {
+	resp := make([]dto.RateOverrideResponse, len(overrides))
+	for i := range overrides {
+		resp[i] = toRateOverrideResponse(&overrides[i])
+	}
+	return resp
+}
+
+// applyRateOverride copies the request into the override.
+func applyRateOverride(o *dbmodel.ExchangeRateOverride, req *dto.RateOverrideRequest) {
+	o.FromCurrency = req.FromCurrency
+	o.ToCurrency = req.ToCurrency
+	o.StartDate = req.StartDate
+	o.EndDate = req.EndDate
+	o.Rate = req.Rate
+	o.Note = req.Note
+}
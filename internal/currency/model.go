@@ -1,4 +1,13 @@
 package currency
 
-// AvailableCurrencies holds supported currency codes
-var AvailableCurrencies = []string{"VND", "USD", "EUR"}
+import "mindoh-service/internal/money"
+
+// AvailableCurrencies holds supported currency codes: every ISO 4217 currency
+// known to the money package.
+var AvailableCurrencies = func() []string {
+	var codes []string
+	for _, c := range money.Currencies() {
+		codes = append(codes, c.Code)
+	}
+	return codes
+}()
@@ -0,0 +1,58 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mindoh-service/config"
+)
+
+// RateProvider fetches the current exchange rates from one source.
+type RateProvider interface {
+	// Name identifies the provider in config.yaml and in logs.
+	Name() string
+	// Fetch returns the value of one unit of each currency in VND and the day
+	// the rates apply to (YYYY-MM-DD, empty for today).
+	Fetch(ctx context.Context) (date string, rates map[string]float64, err error)
+}
+
+// httpClient is shared by the providers that fetch rates over HTTP.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// NewProviders returns the providers listed in the configuration, in the order
+// they are tried. Without a list only the fawazahmed0 API is used.
+func NewProviders(cfg *config.Config) ([]RateProvider, error) {
+	names := strings.Split(cfg.ExchangeRates.Providers, ",")
+	var providers []RateProvider
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case "fawazahmed0":
+			providers = append(providers, NewFawazahmed0Provider())
+		case "static":
+			p, err := NewStaticProvider(cfg.ExchangeRates.StaticFile)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		case "http":
+			h := cfg.ExchangeRates.HTTP
+			p, err := NewHTTPProvider(h.URL, h.Base, h.RatesField, h.DateField, h.BaseField)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		default:
+			return nil, fmt.Errorf("unknown exchange rate provider: %s", name)
+		}
+	}
+	if len(providers) == 0 {
+		providers = append(providers, NewFawazahmed0Provider())
+	}
+	return providers, nil
+}
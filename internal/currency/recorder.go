@@ -0,0 +1,40 @@
+package currency
+
+import (
+	"log/slog"
+	"time"
+)
+
+// RateRecorder periodically stores the live exchange rates as the snapshot of
+// their day, building up the history used for historical conversion.
+type RateRecorder struct {
+	History  *RateHistory
+	Interval time.Duration
+}
+
+func NewRateRecorder(history *RateHistory, interval time.Duration) *RateRecorder {
+	return &RateRecorder{History: history, Interval: interval}
+}
+
+// Start runs the recorder once immediately and then on every tick, in the background.
+func (r *RateRecorder) Start() {
+	go func() {
+		r.run()
+		ticker := time.NewTicker(r.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.run()
+		}
+	}()
+}
+
+func (r *RateRecorder) run() {
+	date, err := r.History.Record()
+	if err != nil {
+		slog.Error("currency: recording exchange rates failed", "error", err)
+		return
+	}
+	if date != "" {
+		slog.Debug("currency: recorded exchange rates", "date", date)
+	}
+}
@@ -0,0 +1,140 @@
+package currency
+
+import (
+	dbmodel "mindoh-service/internal/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateRepository handles DB operations for the daily exchange rate snapshots
+type RateRepository struct {
+	DB *gorm.DB
+}
+
+func NewRateRepository(db *gorm.DB) *RateRepository {
+	return &RateRepository{DB: db}
+}
+
+// Save stores the rates, replacing the snapshot of the same currency and date.
+func (r *RateRepository) Save(rates []dbmodel.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(&rates).Error
+}
+
+// ListRange returns the snapshots of the currencies dated within [from, to],
+// plus the latest snapshot of each currency before from. Empty bounds are
+// open.
+func (r *RateRepository) ListRange(currencies []string, from, to string) ([]dbmodel.ExchangeRate, error) {
+	var before []dbmodel.ExchangeRate
+	if from != "" {
+		err := r.DB.Raw("SELECT DISTINCT ON (currency) * FROM exchange_rates WHERE currency IN ? AND date < ? ORDER BY currency, date DESC", currencies, from).
+			Scan(&before).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	q := r.DB.Where("currency IN ?", currencies)
+	if from != "" {
+		q = q.Where("date >= ?", from)
+	}
+	if to != "" {
+		q = q.Where("date <= ?", to)
+	}
+	var rates []dbmodel.ExchangeRate
+	if err := q.Order("currency, date").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return append(before, rates...), nil
+}
+
+// ListOverrides returns the rate overrides, optionally only those involving
+// currency, newest first.
+func (r *RateRepository) ListOverrides(currency string) ([]dbmodel.ExchangeRateOverride, error) {
+	q := r.DB.Order("id DESC")
+	if currency != "" {
+		q = q.Where("from_currency = ? OR to_currency = ?", currency, currency)
+	}
+	var overrides []dbmodel.ExchangeRateOverride
+	err := q.Find(&overrides).Error
+	return overrides, err
+}
+
+// ListOverridesInRange returns the overrides between two of the currencies
+// whose date range overlaps [from, to], newest first. Empty bounds are open.
+func (r *RateRepository) ListOverridesInRange(currencies []string, from, to string) ([]dbmodel.ExchangeRateOverride, error) {
+	q := r.DB.Where("from_currency IN ? AND to_currency IN ?", currencies, currencies)
+	if from != "" {
+		q = q.Where("end_date >= ?", from)
+	}
+	if to != "" {
+		q = q.Where("start_date <= ?", to)
+	}
+	var overrides []dbmodel.ExchangeRateOverride
+	err := q.Order("id DESC").Find(&overrides).Error
+	return overrides, err
+}
+
+// ListOverridesFor returns the overrides between currency and any other
+// currency that apply on date, newest first.
+func (r *RateRepository) ListOverridesFor(currency, date string) ([]dbmodel.ExchangeRateOverride, error) {
+	var overrides []dbmodel.ExchangeRateOverride
+	err := r.DB.Where("(from_currency = ? OR to_currency = ?) AND start_date <= ? AND end_date >= ?", currency, currency, date, date).
+		Order("id DESC").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *RateRepository) GetOverride(id uint) (*dbmodel.ExchangeRateOverride, error) {
+	var override dbmodel.ExchangeRateOverride
+	if err := r.DB.First(&override, id).Error; err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *RateRepository) SaveOverride(override *dbmodel.ExchangeRateOverride) error {
+	return r.DB.Save(override).Error
+}
+
+func (r *RateRepository) DeleteOverride(id uint) error {
+	return r.DB.Delete(&dbmodel.ExchangeRateOverride{}, id).Error
+}
+
+// FavoriteRepository handles DB operations for the users' favorite currencies
+type FavoriteRepository struct {
+	DB *gorm.DB
+}
+
+func NewFavoriteRepository(db *gorm.DB) *FavoriteRepository {
+	return &FavoriteRepository{DB: db}
+}
+
+// List returns the codes of the user's favorite currencies in their order.
+func (r *FavoriteRepository) List(userID uint) ([]string, error) {
+	var codes []string
+	err := r.DB.Model(&dbmodel.FavoriteCurrency{}).Where("user_id = ?", userID).
+		Order("position, id").Pluck("currency", &codes).Error
+	return codes, err
+}
+
+// Replace sets the user's favorite currencies to codes, in that order.
+func (r *FavoriteRepository) Replace(userID uint, codes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&dbmodel.FavoriteCurrency{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		favorites := make([]dbmodel.FavoriteCurrency, len(codes))
+		for i, code := range codes {
+			favorites[i] = dbmodel.FavoriteCurrency{UserID: userID, Currency: code, Position: i}
+		}
+		return tx.Create(&favorites).Error
+	})
+}
@@ -6,12 +6,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterCurrencyRoutes(r *gin.Engine, a auth.IAuthService, resolveUser func(string) (uint, error)) {
-	handler := NewCurrencyHandler()
+func RegisterCurrencyRoutes(r *gin.Engine, a auth.IAuthService, service *CurrencyService, resolveUser func(string) (uint, error)) {
+	handler := NewCurrencyHandler(service)
 	group := r.Group("/api/currency")
 	group.Use(a.AuthMiddleware(resolveUser))
 	{
 		group.GET("/exchange-rates", handler.GetExchangeRates)
 		group.GET("/currencies", handler.GetAvailableCurrencies)
+		group.GET("/favorites", handler.GetFavoriteCurrencies)
+		group.PUT("/favorites", handler.SetFavoriteCurrencies)
+		group.GET("/convert", handler.Convert)
+	}
+
+	admin := r.Group("/api/admin/currency")
+	admin.Use(a.AuthMiddleware(resolveUser), a.RoleGuard(auth.RoleAdmin))
+	{
+		admin.GET("/overrides", handler.ListRateOverrides)
+		admin.POST("/overrides", handler.CreateRateOverride)
+		admin.PUT("/overrides/:id", handler.UpdateRateOverride)
+		admin.DELETE("/overrides/:id", handler.DeleteRateOverride)
 	}
 }
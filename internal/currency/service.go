@@ -0,0 +1,179 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoRate is returned when no exchange rate is known for a currency.
+var ErrNoRate = errors.New("no exchange rate")
+
+// CurrencyService serves the exchange rates, conversions at past dates and
+// the currency list with each user's favorites.
+type CurrencyService struct {
+	Rates     *ExchangeRateService
+	History   *RateHistory
+	Favorites *FavoriteRepository
+}
+
+func NewCurrencyService(rates *ExchangeRateService, history *RateHistory, favorites *FavoriteRepository) *CurrencyService {
+	return &CurrencyService{Rates: rates, History: history, Favorites: favorites}
+}
+
+// ListCurrencies returns every known currency: the user's favorites first, in
+// their order, then the others by code.
+func (s *CurrencyService) ListCurrencies(userID uint) ([]dto.CurrencyResponse, error) {
+	favorites, err := s.Favorites.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	all := money.Currencies()
+	list := make([]dto.CurrencyResponse, 0, len(all))
+	pinned := make(map[string]bool, len(favorites))
+	for _, code := range favorites {
+		if c, ok := money.Lookup(code); ok && !pinned[c.Code] {
+			pinned[c.Code] = true
+			list = append(list, toCurrencyResponse(c, true))
+		}
+	}
+	for _, c := range all {
+		if !pinned[c.Code] {
+			list = append(list, toCurrencyResponse(c, false))
+		}
+	}
+	return list, nil
+}
+
+// SetFavorites replaces the user's favorite currencies with codes, in that
+// order. Codes are upper-cased and repeats dropped; an unknown code is an
+// error. It returns the stored list.
+func (s *CurrencyService) SetFavorites(userID uint, codes []string) ([]string, error) {
+	favorites := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if err := money.ValidateCurrency(code); err != nil {
+			return nil, err
+		}
+		if !seen[code] {
+			seen[code] = true
+			favorites = append(favorites, code)
+		}
+	}
+	if err := s.Favorites.Replace(userID, favorites); err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+// Convert expresses amount (in from) in to at the rates of date. A manual
+// override of the pair takes precedence; otherwise the stored snapshots are
+// used, falling back to the live rate for a currency without any.
+func (s *CurrencyService) Convert(amount decimal.Decimal, from, to, date string) (*dto.ConvertResponse, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	for _, code := range []string{from, to} {
+		if err := money.ValidateCurrency(code); err != nil {
+			return nil, err
+		}
+	}
+	resp := &dto.ConvertResponse{Amount: amount, From: from, To: to, Date: date}
+	if from == to {
+		resp.Rate = decimal.NewFromInt(1)
+		resp.Converted = amount
+		resp.Source = "identity"
+		return resp, nil
+	}
+	history, err := s.History.Load([]string{from, to}, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if rate, ok := history.Override(from, to, date); ok {
+		resp.Rate = rate
+		resp.Converted = money.Round(amount.Mul(rate), to)
+		resp.Source = "override"
+		return resp, nil
+	}
+
+	resp.Source = "history"
+	var live map[string]float64
+	rateOf := func(code string) (decimal.Decimal, error) {
+		if rate, ok := history.At(code, date); ok {
+			return rate, nil
+		}
+		if live == nil {
+			live = s.Rates.GetRates()
+		}
+		if rate := live[code]; rate > 0 {
+			resp.Source = "live"
+			return decimal.NewFromFloat(rate), nil
+		}
+		return decimal.Zero, fmt.Errorf("%w for %s", ErrNoRate, code)
+	}
+	fromRate, err := rateOf(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := rateOf(to)
+	if err != nil {
+		return nil, err
+	}
+	resp.Rate = fromRate.DivRound(toRate, ratioScale)
+	resp.Converted = money.Convert(amount, fromRate, toRate, to)
+	return resp, nil
+}
+
+// ListOverrides returns the manual rate overrides, optionally only those
+// involving currency, newest first.
+func (s *CurrencyService) ListOverrides(currency string) ([]dbmodel.ExchangeRateOverride, error) {
+	return s.History.Repo.ListOverrides(strings.ToUpper(currency))
+}
+
+func (s *CurrencyService) GetOverride(id uint) (*dbmodel.ExchangeRateOverride, error) {
+	return s.History.Repo.GetOverride(id)
+}
+
+// SaveOverride validates and stores a new or changed override.
+func (s *CurrencyService) SaveOverride(override *dbmodel.ExchangeRateOverride) error {
+	if err := validateOverride(override); err != nil {
+		return err
+	}
+	return s.History.Repo.SaveOverride(override)
+}
+
+func (s *CurrencyService) DeleteOverride(id uint) error {
+	return s.History.Repo.DeleteOverride(id)
+}
+
+func validateOverride(o *dbmodel.ExchangeRateOverride) error {
+	o.FromCurrency = strings.ToUpper(o.FromCurrency)
+	o.ToCurrency = strings.ToUpper(o.ToCurrency)
+	for _, code := range []string{o.FromCurrency, o.ToCurrency} {
+		if err := money.ValidateCurrency(code); err != nil {
+			return err
+		}
+	}
+	if o.FromCurrency == o.ToCurrency {
+		return errors.New("from_currency and to_currency must differ")
+	}
+	for _, date := range []string{o.StartDate, o.EndDate} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if o.EndDate < o.StartDate {
+		return errors.New("end_date must not be before start_date")
+	}
+	if !o.Rate.IsPositive() {
+		return errors.New("rate must be positive")
+	}
+	o.Rate = o.Rate.Round(rateScale)
+	return nil
+}
@@ -0,0 +1,61 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StaticProvider reads rates from a local YAML or JSON file, for tests and
+// deployments without internet access. The file is read on every fetch, so
+// edits are picked up on the next refresh:
+//
+//	date: "2024-06-01" # optional, defaults to today
+//	rates:             # value of one unit in VND
+//	  USD: 25400
+//	  EUR: 27500
+type StaticProvider struct {
+	Path string
+}
+
+type staticRates struct {
+	Date  string             `yaml:"date"`
+	Rates map[string]float64 `yaml:"rates"`
+}
+
+// NewStaticProvider checks that the file at path can be read.
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	if path == "" {
+		return nil, errors.New("static exchange rate provider needs a file")
+	}
+	p := &StaticProvider{Path: path}
+	if _, _, err := p.Fetch(context.Background()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *StaticProvider) Name() string { return "static" }
+
+func (p *StaticProvider) Fetch(ctx context.Context) (string, map[string]float64, error) {
+	content, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	var file staticRates
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return "", nil, fmt.Errorf("parse %s: %w", p.Path, err)
+	}
+	rates := map[string]float64{"VND": 1}
+	for code, rate := range file.Rates {
+		if rate <= 0 {
+			return "", nil, fmt.Errorf("%s: rate of %s must be positive", p.Path, code)
+		}
+		rates[strings.ToUpper(code)] = rate
+	}
+	return file.Date, rates, nil
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Account is the database model for a per-user wallet or bank account
+// that expenses are paid from or received into.
+type Account struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	UserID         uint            `gorm:"not null;uniqueIndex:idx_accounts_user_name,where:deleted_at IS NULL" json:"user_id"`
+	Name           string          `gorm:"type:varchar(64);not null;uniqueIndex:idx_accounts_user_name,where:deleted_at IS NULL" json:"name"`
+	Currency       string          `gorm:"type:varchar(3);not null" json:"currency"`
+	OpeningBalance decimal.Decimal `gorm:"type:numeric(20,4);not null;default:0" json:"opening_balance"`
+	Archived       bool            `gorm:"not null;default:false" json:"archived"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`
+}
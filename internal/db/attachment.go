@@ -0,0 +1,18 @@
+package db
+
+import "time"
+
+// Attachment is the metadata of a file (receipt photo, invoice, ...) attached
+// to an expense. The content itself lives in the configured file storage under
+// StorageKey.
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ExpenseID   uint      `gorm:"not null;index" json:"expense_id"`
+	Expense     *Expense  `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	FileName    string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType string    `gorm:"type:varchar(127);not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Budget is the database model for a monthly spending limit.
+// An empty Type means the budget covers all expense types.
+type Budget struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	UserID     uint            `gorm:"not null;uniqueIndex:idx_budgets_user_type,where:deleted_at IS NULL" json:"user_id"`
+	Type       string          `gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_budgets_user_type,where:deleted_at IS NULL" json:"type"`
+	Amount     decimal.Decimal `gorm:"type:numeric(20,4);not null" json:"amount"` // Monthly limit, positive
+	Currency   string          `gorm:"type:varchar(3);not null" json:"currency"`
+	StartMonth string          `gorm:"type:varchar(7)" json:"start_month"` // Format: YYYY-MM, empty = no lower bound
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Category is the database model for an expense or income category. Categories
+// form a tree through ParentID and are kind-specific. Built-in defaults have no
+// UserID and are shared by every user; users add their own custom categories,
+// optionally nested under a default one.
+type Category struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserID    *uint          `gorm:"index;uniqueIndex:idx_categories_user_kind_name,where:deleted_at IS NULL" json:"user_id"`
+	ParentID  *uint          `gorm:"index" json:"parent_id"`
+	Name      string         `gorm:"type:varchar(32);not null;uniqueIndex:idx_categories_user_kind_name,where:deleted_at IS NULL" json:"name"`
+	Kind      ExpenseKind    `gorm:"type:varchar(32);not null;uniqueIndex:idx_categories_user_kind_name,where:deleted_at IS NULL" json:"kind"`
+	Icon      string         `gorm:"type:varchar(64)" json:"icon"`
+	Color     string         `gorm:"type:varchar(16)" json:"color"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// defaultCategory describes a built-in top-level category and its children.
+type defaultCategory struct {
+	Name     string
+	Kind     ExpenseKind
+	Icon     string
+	Color    string
+	Children []string
+}
+
+// defaultCategories is the built-in tree seeded on first start.
+var defaultCategories = []defaultCategory{
+	{Name: "Food", Kind: ExpenseKindExpense, Icon: "utensils", Color: "#F59E0B", Children: []string{"Groceries", "Restaurants", "Coffee"}},
+	{Name: "Transport", Kind: ExpenseKindExpense, Icon: "car", Color: "#3B82F6", Children: []string{"Fuel", "Taxi", "Parking", "Public transport"}},
+	{Name: "Housing", Kind: ExpenseKindExpense, Icon: "home", Color: "#8B5CF6", Children: []string{"Rent", "Utilities", "Internet", "Maintenance"}},
+	{Name: "Shopping", Kind: ExpenseKindExpense, Icon: "shopping-bag", Color: "#EC4899", Children: []string{"Clothes", "Electronics", "Household"}},
+	{Name: "Health", Kind: ExpenseKindExpense, Icon: "heart-pulse", Color: "#EF4444", Children: []string{"Medicine", "Doctor", "Insurance"}},
+	{Name: "Entertainment", Kind: ExpenseKindExpense, Icon: "film", Color: "#14B8A6", Children: []string{"Movies", "Games", "Subscriptions"}},
+	{Name: "Education", Kind: ExpenseKindExpense, Icon: "book", Color: "#6366F1", Children: []string{"Tuition", "Books", "Courses"}},
+	{Name: "Travel", Kind: ExpenseKindExpense, Icon: "plane", Color: "#0EA5E9", Children: []string{"Flights", "Hotels"}},
+	{Name: "Family", Kind: ExpenseKindExpense, Icon: "users", Color: "#F97316", Children: []string{"Children", "Pets", "Gifts"}},
+	{Name: "Other", Kind: ExpenseKindExpense, Icon: "dots", Color: "#6B7280"},
+	{Name: "Salary", Kind: ExpenseKindIncome, Icon: "briefcase", Color: "#22C55E", Children: []string{"Bonus"}},
+	{Name: "Business", Kind: ExpenseKindIncome, Icon: "store", Color: "#10B981"},
+	{Name: "Investment", Kind: ExpenseKindIncome, Icon: "chart-line", Color: "#84CC16", Children: []string{"Interest", "Dividends"}},
+	{Name: "Gift", Kind: ExpenseKindIncome, Icon: "gift", Color: "#A855F7"},
+	{Name: "Refund", Kind: ExpenseKindIncome, Icon: "rotate-left", Color: "#06B6D4"},
+	{Name: "Other income", Kind: ExpenseKindIncome, Icon: "dots", Color: "#6B7280"},
+}
+
+// TypeAliases maps common free-text expense types to the name of the default
+// category they belong to. Types are compared lower-cased.
+var TypeAliases = map[string]string{
+	"foods":          "food",
+	"eating out":     "restaurants",
+	"eat out":        "restaurants",
+	"restaurant":     "restaurants",
+	"dining":         "restaurants",
+	"an uong":        "food",
+	"grocery":        "groceries",
+	"market":         "groceries",
+	"cafe":           "coffee",
+	"transportation": "transport",
+	"gas":            "fuel",
+	"petrol":         "fuel",
+	"xang":           "fuel",
+	"grab":           "taxi",
+	"bus":            "public transport",
+	"electricity":    "utilities",
+	"water":          "utilities",
+	"bills":          "utilities",
+	"wifi":           "internet",
+	"clothing":       "clothes",
+	"medical":        "health",
+	"healthcare":     "health",
+	"pharmacy":       "medicine",
+	"fun":            "entertainment",
+	"subscription":   "subscriptions",
+	"school":         "tuition",
+	"hotel":          "hotels",
+	"flight":         "flights",
+	"kids":           "children",
+	"pet":            "pets",
+	"wage":           "salary",
+	"wages":          "salary",
+	"luong":          "salary",
+	"thuong":         "bonus",
+	"gifts":          "gift",
+	"dividend":       "dividends",
+}
+
+// CategoryNameForType returns the lower-cased category name a free-text type
+// maps to: its alias when there is one, otherwise the type itself.
+func CategoryNameForType(t string) string {
+	if name, ok := TypeAliases[t]; ok {
+		return name
+	}
+	return t
+}
@@ -0,0 +1,10 @@
+package db
+
+import "time"
+
+// DataMigration records a one-off data migration that has been applied, for
+// steps that cannot cheaply tell from the data whether they still apply.
+type DataMigration struct {
+	Name      string    `gorm:"primaryKey;type:varchar(100)" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
@@ -13,7 +13,7 @@ var DB *gorm.DB
 func ConnectDatabase(cfg *config.Config) {
 	dsn := cfg.GetDSN()
 	slog.Info("connecting to database", "host", cfg.DB.Host, "port", cfg.DB.Port, "name", cfg.DB.Name)
-	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		slog.Error("failed to connect to database", "error", err)
 		panic("database connection failed")
@@ -22,10 +22,14 @@ func ConnectDatabase(cfg *config.Config) {
 	slog.Info("database connected")
 
 	// Auto-migrate models
-	if err := DB.AutoMigrate(&User{}, &Expense{}); err != nil {
+	if err := DB.AutoMigrate(&User{}, &Account{}, &Tag{}, &Category{}, &Expense{}, &ExpenseSplit{}, &ExpenseRevision{}, &Attachment{}, &Transfer{}, &RecurringRule{}, &Budget{}, &ExchangeRate{}, &ExchangeRateOverride{}, &FavoriteCurrency{}, &DataMigration{}); err != nil {
 		slog.Error("failed to migrate database", "error", err)
 		panic("database migration failed")
 	}
+	if err := runDataMigrations(DB); err != nil {
+		slog.Error("failed to run data migrations", "error", err)
+		panic("database migration failed")
+	}
 	slog.Info("database migration ok")
 }
 
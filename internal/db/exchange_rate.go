@@ -0,0 +1,34 @@
+package db
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeRate is the daily snapshot of one currency's exchange rate: the
+// value of one unit of Currency in VND on Date.
+type ExchangeRate struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	Currency  string          `gorm:"type:varchar(3);not null;uniqueIndex:idx_exchange_rates_currency_date,priority:1" json:"currency"`
+	Date      string          `gorm:"type:varchar(10);not null;uniqueIndex:idx_exchange_rates_currency_date,priority:2" json:"date"` // Format: YYYY-MM-DD
+	Rate      decimal.Decimal `gorm:"type:numeric(24,10);not null" json:"rate"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ExchangeRateOverride is a manually set rate for a currency pair, such as a
+// bank's actual card rate, applying from StartDate to EndDate inclusive. It
+// takes precedence over the provider snapshots, in both directions of the
+// pair. Rate is the number of units of ToCurrency per unit of FromCurrency.
+type ExchangeRateOverride struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	FromCurrency string          `gorm:"type:varchar(3);not null;index:idx_exchange_rate_overrides_pair,priority:1" json:"from_currency"`
+	ToCurrency   string          `gorm:"type:varchar(3);not null;index:idx_exchange_rate_overrides_pair,priority:2" json:"to_currency"`
+	StartDate    string          `gorm:"type:varchar(10);not null" json:"start_date"` // Format: YYYY-MM-DD
+	EndDate      string          `gorm:"type:varchar(10);not null" json:"end_date"`   // Format: YYYY-MM-DD
+	Rate         decimal.Decimal `gorm:"type:numeric(24,10);not null" json:"rate"`
+	Note         string          `gorm:"type:varchar(255)" json:"note,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
@@ -3,39 +3,51 @@ package db
 import (
 	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 type ExpenseKind string
 
 const (
-	ExpenseKindExpense ExpenseKind = "expense"
-	ExpenseKindIncome  ExpenseKind = "income"
+	ExpenseKindExpense  ExpenseKind = "expense"
+	ExpenseKindIncome   ExpenseKind = "income"
+	ExpenseKindTransfer ExpenseKind = "transfer" // one leg of a Transfer; not counted as income or expense
 )
 
-type ExpenseResource string
-
-const (
-	ExpenseResourceCash   ExpenseResource = "CASH"
-	ExpenseResourceCake   ExpenseResource = "CAKE"
-	ExpenseResourceVCB    ExpenseResource = "VCB"
-	ExpenseResourceVPBank ExpenseResource = "VPBANK"
-	ExpenseResourceBIDV   ExpenseResource = "BIDV"
-	ExpenseResourceOther  ExpenseResource = "OTHER"
-)
+// ExpenseSearchVector is the full-text document of an expense: its description
+// and type, tokenized without stemming so Vietnamese and English words both
+// match as typed. Queries must use the same expression to hit the GIN index.
+const ExpenseSearchVector = "to_tsvector('simple', coalesce(description, '') || ' ' || type)"
 
 // Expense is the database model for an expense or income record.
 type Expense struct {
 	ID          uint            `gorm:"primaryKey" json:"id"`
-	UserID      uint            `gorm:"not null" json:"user_id"`
-	Amount      float64         `gorm:"not null" json:"amount"`
+	UserID      uint            `gorm:"not null;uniqueIndex:idx_expenses_external,priority:1" json:"user_id"`
+	Amount      decimal.Decimal `gorm:"type:numeric(20,4);not null" json:"amount"`
 	Currency    string          `gorm:"type:varchar(3);not null" json:"currency"`
 	Kind        ExpenseKind     `gorm:"type:varchar(32);not null" json:"kind"`
 	Type        string          `gorm:"type:varchar(32);not null" json:"type"`
-	Resource    ExpenseResource `gorm:"type:varchar(32)" json:"resource"`
+	CategoryID  *uint           `gorm:"index" json:"category_id"`
+	Category    *Category       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	AccountID   *uint           `gorm:"index;uniqueIndex:idx_expenses_external,priority:2" json:"account_id"`
+	Account     *Account        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	Tags        []Tag           `gorm:"many2many:expense_tags;constraint:OnDelete:CASCADE" json:"tags,omitempty"`
+	Splits      []ExpenseSplit  `gorm:"constraint:OnDelete:CASCADE" json:"splits,omitempty"`
 	Description string          `gorm:"type:text" json:"description"`
-	Date        string          `gorm:"type:varchar(10);not null" json:"date"` // Format: YYYY-MM-DD
+	Date        string          `gorm:"type:varchar(10);not null;uniqueIndex:idx_expenses_recurring_occurrence,priority:2" json:"date"` // Format: YYYY-MM-DD
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`
+
+	// RecurringRuleID links an expense generated by a recurring rule back to it.
+	// Together with Date it is unique, so an occurrence is never created twice.
+	RecurringRuleID *uint `gorm:"uniqueIndex:idx_expenses_recurring_occurrence,priority:1" json:"recurring_rule_id,omitempty"`
+
+	// TransferID is set on both legs of a transfer between accounts.
+	TransferID *uint `gorm:"index" json:"transfer_id,omitempty"`
+
+	// ExternalID identifies an imported row in its source statement (e.g. the OFX
+	// FITID). It is unique per user and account so re-imports skip it.
+	ExternalID *string `gorm:"type:varchar(255);uniqueIndex:idx_expenses_external,priority:3" json:"external_id,omitempty"`
 }
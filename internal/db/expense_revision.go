@@ -0,0 +1,30 @@
+package db
+
+import "time"
+
+// RevisionAction is what happened to an expense in an ExpenseRevision.
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+	RevisionRevert  RevisionAction = "revert"
+)
+
+// ExpenseRevision records one change of an expense: who made it, when, and the
+// old and new value of every field that changed. State holds all tracked
+// fields after the change (before it, for a delete), so the expense can be
+// reverted to any revision.
+type ExpenseRevision struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	ExpenseID  uint           `gorm:"not null;index" json:"expense_id"`
+	Expense    *Expense       `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	ActorID    *uint          `json:"actor_id"` // nil for changes made by the service itself, e.g. recurring rules
+	Action     RevisionAction `gorm:"type:varchar(16);not null" json:"action"`
+	RevertedTo *uint          `json:"reverted_to,omitempty"`              // revision restored by a revert
+	Changes    string         `gorm:"type:jsonb;not null" json:"changes"` // {"field": {"old": ..., "new": ...}}
+	State      string         `gorm:"type:jsonb;not null" json:"state"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package db
+
+import "github.com/shopspring/decimal"
+
+// ExpenseSplit is one line of an expense that is split across several types,
+// e.g. the groceries and household parts of a supermarket receipt. The line
+// amounts of an expense add up to its Amount and carry the same sign.
+type ExpenseSplit struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	ExpenseID   uint            `gorm:"not null;index" json:"expense_id"`
+	Amount      decimal.Decimal `gorm:"type:numeric(20,4);not null" json:"amount"`
+	Type        string          `gorm:"type:varchar(32);not null" json:"type"`
+	CategoryID  *uint           `gorm:"index" json:"category_id"`
+	Category    *Category       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	Description string          `gorm:"type:text" json:"description"`
+}
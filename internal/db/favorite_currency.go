@@ -0,0 +1,13 @@
+package db
+
+import "time"
+
+// FavoriteCurrency is a currency a user pinned to the top of the currency
+// list. Position orders the user's favorites, starting at 0.
+type FavoriteCurrency struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_favorite_currencies_user_currency" json:"user_id"`
+	Currency  string    `gorm:"type:varchar(3);not null;uniqueIndex:idx_favorite_currencies_user_currency" json:"currency"`
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"log/slog"
+	"time"
+
+	"mindoh-service/internal/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// runDataMigrations performs one-off data migrations that AutoMigrate cannot express.
+// Each step checks whether it still applies, or is recorded as a DataMigration
+// once done, so running it on every start is safe.
+func runDataMigrations(db *gorm.DB) error {
+	if err := migrateResourcesToAccounts(db); err != nil {
+		return err
+	}
+	if err := seedDefaultCategories(db); err != nil {
+		return err
+	}
+	if err := migrateTypesToCategories(db); err != nil {
+		return err
+	}
+	if err := runOnce(db, "round_amounts_to_minor_units", roundAmountsToMinorUnits); err != nil {
+		return err
+	}
+	if err := createSearchIndex(db); err != nil {
+		return err
+	}
+	return createExternalIDIndex(db)
+}
+
+// runOnce runs migrate in a transaction unless a DataMigration named name
+// exists, and records it afterwards.
+func runOnce(db *gorm.DB, name string, migrate func(tx *gorm.DB) error) error {
+	var count int64
+	if err := db.Model(&DataMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&DataMigration{Name: name, AppliedAt: time.Now()}).Error
+	})
+}
+
+// createSearchIndex adds the GIN index used by the full-text expense search.
+func createSearchIndex(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_expenses_search ON expenses USING GIN (" + ExpenseSearchVector + ")").Error
+}
+
+// createExternalIDIndex makes external IDs unique per user among expenses
+// without an account. idx_expenses_external does not cover them because
+// Postgres treats NULL account IDs as distinct. Duplicates left by earlier
+// concurrent imports keep their oldest row's external ID; the others lose it.
+// Nothing is done once the index exists.
+func createExternalIDIndex(db *gorm.DB) error {
+	if db.Migrator().HasIndex("expenses", "idx_expenses_external_no_account") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`UPDATE expenses SET external_id = NULL
+			WHERE account_id IS NULL AND external_id IS NOT NULL AND id NOT IN (
+				SELECT MIN(id) FROM expenses
+				WHERE account_id IS NULL AND external_id IS NOT NULL
+				GROUP BY user_id, external_id)`)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			slog.Warn("cleared duplicate external IDs of expenses without an account", "rows", res.RowsAffected)
+		}
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_expenses_external_no_account ON expenses (user_id, external_id) WHERE account_id IS NULL AND external_id IS NOT NULL").Error
+	})
+}
+
+// roundAmountsToMinorUnits rounds amounts stored before the switch from
+// float to NUMERIC columns to the minor unit of their currency, e.g.
+// 99.99000000001 USD becomes 99.99 and 120000.4 VND becomes 120000. Split
+// lines use the currency of their expense. It scans every amount, so it runs
+// once through runOnce; amounts saved since are validated to the minor unit.
+func roundAmountsToMinorUnits(tx *gorm.DB) error {
+	var total int64
+	for _, t := range []struct{ table, column string }{
+		{"expenses", "amount"},
+		{"recurring_rules", "amount"},
+		{"budgets", "amount"},
+		{"accounts", "opening_balance"},
+	} {
+		rounded := "ROUND(" + t.column + ", " + money.ScaleSQL("currency") + ")"
+		res := tx.Exec("UPDATE " + t.table + " SET " + t.column + " = " + rounded + " WHERE " + t.column + " <> " + rounded)
+		if res.Error != nil {
+			return res.Error
+		}
+		total += res.RowsAffected
+	}
+	rounded := "ROUND(s.amount, " + money.ScaleSQL("e.currency") + ")"
+	res := tx.Exec("UPDATE expense_splits s SET amount = " + rounded + " FROM expenses e WHERE e.id = s.expense_id AND s.amount <> " + rounded)
+	if res.Error != nil {
+		return res.Error
+	}
+	total += res.RowsAffected
+	if total > 0 {
+		slog.Info("rounded amounts to currency minor units", "rows", total)
+	}
+	return nil
+}
+
+// migrateResourcesToAccounts converts the legacy free-text `resource` column
+// (CASH, VCB, ...) on expenses and recurring rules into per-user accounts named
+// after the old value, links the rows through account_id and drops the column.
+func migrateResourcesToAccounts(db *gorm.DB) error {
+	for _, table := range []string{"expenses", "recurring_rules"} {
+		if !db.Migrator().HasColumn(table, "resource") {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			type legacyResource struct {
+				UserID   uint   `gorm:"column:user_id"`
+				Resource string `gorm:"column:resource"`
+				Currency string `gorm:"column:currency"`
+			}
+			var rows []legacyResource
+			err := tx.Table(table).
+				Select("user_id, resource, MODE() WITHIN GROUP (ORDER BY currency) AS currency").
+				Where("resource IS NOT NULL AND resource <> '' AND account_id IS NULL").
+				Group("user_id, resource").
+				Scan(&rows).Error
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				account := Account{}
+				err := tx.Where(Account{UserID: row.UserID, Name: row.Resource}).
+					Attrs(Account{Currency: row.Currency}).
+					FirstOrCreate(&account).Error
+				if err != nil {
+					return err
+				}
+				err = tx.Table(table).
+					Where("user_id = ? AND resource = ? AND account_id IS NULL", row.UserID, row.Resource).
+					Update("account_id", account.ID).Error
+				if err != nil {
+					return err
+				}
+			}
+			slog.Info("migrated legacy resources to accounts", "table", table, "accounts", len(rows))
+			return tx.Migrator().DropColumn(table, "resource")
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedDefaultCategories creates the built-in category tree when no default
+// categories exist yet.
+func seedDefaultCategories(db *gorm.DB) error {
+	var count int64
+	if err := db.Unscoped().Model(&Category{}).Where("user_id IS NULL").Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, def := range defaultCategories {
+			parent := Category{Name: def.Name, Kind: def.Kind, Icon: def.Icon, Color: def.Color}
+			if err := tx.Create(&parent).Error; err != nil {
+				return err
+			}
+			for _, name := range def.Children {
+				child := Category{ParentID: &parent.ID, Name: name, Kind: def.Kind, Icon: def.Icon, Color: def.Color}
+				if err := tx.Create(&child).Error; err != nil {
+					return err
+				}
+			}
+		}
+		slog.Info("seeded default categories", "categories", len(defaultCategories))
+		return nil
+	})
+}
+
+// migrateTypesToCategories links uncategorised expenses to a category by their
+// free-text type. A type matches a default category by name or through
+// TypeAliases; any other type becomes a top-level custom category of the user.
+// The type column itself is left untouched.
+func migrateTypesToCategories(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		type legacyType struct {
+			UserID uint        `gorm:"column:user_id"`
+			Kind   ExpenseKind `gorm:"column:kind"`
+			Type   string      `gorm:"column:type"`
+		}
+		var rows []legacyType
+		err := tx.Unscoped().Model(&Expense{}).
+			Select("DISTINCT user_id, kind, type").
+			Where("category_id IS NULL AND type <> '' AND kind IN ?", []ExpenseKind{ExpenseKindExpense, ExpenseKindIncome}).
+			Scan(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		for _, row := range rows {
+			var category Category
+			err := tx.Where("user_id IS NULL AND kind = ? AND LOWER(name) IN ?", row.Kind, []string{row.Type, CategoryNameForType(row.Type)}).
+				Order(clause.OrderBy{Expression: clause.Expr{SQL: "LOWER(name) = ? DESC, id", Vars: []interface{}{row.Type}}}).
+				Limit(1).
+				Find(&category).Error
+			if err != nil {
+				return err
+			}
+			if category.ID == 0 {
+				userID := row.UserID
+				err = tx.Where("user_id = ? AND kind = ? AND LOWER(name) = ?", userID, row.Kind, row.Type).
+					Attrs(Category{UserID: &userID, Kind: row.Kind, Name: row.Type}).
+					FirstOrCreate(&category).Error
+				if err != nil {
+					return err
+				}
+			}
+			err = tx.Unscoped().Model(&Expense{}).
+				Where("user_id = ? AND kind = ? AND type = ? AND category_id IS NULL", row.UserID, row.Kind, row.Type).
+				Update("category_id", category.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		slog.Info("migrated expense types to categories", "types", len(rows))
+		return nil
+	})
+}
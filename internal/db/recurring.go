@@ -0,0 +1,48 @@
+package db
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// RecurrenceFrequency mirrors the RRULE FREQ values supported by the generator.
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+	RecurrenceYearly  RecurrenceFrequency = "YEARLY"
+)
+
+// RecurringRule is the database model for a recurring expense or income template.
+// The generator materializes one Expense per occurrence, every Interval units of
+// Frequency starting at StartDate, until EndDate (inclusive) when set.
+type RecurringRule struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index" json:"user_id"`
+
+	// Template fields copied onto each generated expense.
+	Amount      decimal.Decimal `gorm:"type:numeric(20,4);not null" json:"amount"`
+	Currency    string          `gorm:"type:varchar(3);not null" json:"currency"`
+	Kind        ExpenseKind     `gorm:"type:varchar(32);not null" json:"kind"`
+	Type        string          `gorm:"type:varchar(32);not null" json:"type"`
+	AccountID   *uint           `gorm:"index" json:"account_id"`
+	Account     *Account        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	Description string          `gorm:"type:text" json:"description"`
+
+	// Schedule
+	Frequency       RecurrenceFrequency `gorm:"type:varchar(16);not null" json:"frequency"`
+	Interval        int                 `gorm:"not null;default:1" json:"interval"`
+	StartDate       string              `gorm:"type:varchar(10);not null" json:"start_date"` // Format: YYYY-MM-DD
+	EndDate         string              `gorm:"type:varchar(10)" json:"end_date"`            // Empty = no end
+	NextRun         string              `gorm:"type:varchar(10);index" json:"next_run"`      // Empty = finished
+	OccurrenceCount int                 `gorm:"not null;default:0" json:"occurrence_count"`  // Occurrences already scheduled
+	Paused          bool                `gorm:"not null;default:false" json:"paused"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
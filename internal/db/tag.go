@@ -0,0 +1,13 @@
+package db
+
+import "time"
+
+// Tag is a user-defined label. An expense can carry many tags and a tag can be
+// on many expenses (join table expense_tags). Names are stored lower-case.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name      string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Transfer links the two legs of a money movement between accounts: a debit
+// Expense on the source account and a credit Expense on the destination account,
+// both with Kind = transfer. Legs may be in different currencies.
+type Transfer struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	UserID        uint           `gorm:"not null;index" json:"user_id"`
+	FromAccountID uint           `gorm:"not null" json:"from_account_id"`
+	ToAccountID   uint           `gorm:"not null" json:"to_account_id"`
+	Description   string         `gorm:"type:text" json:"description"`
+	Date          string         `gorm:"type:varchar(10);not null" json:"date"` // Format: YYYY-MM-DD
+	Legs          []Expense      `gorm:"foreignKey:TransferID" json:"legs,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
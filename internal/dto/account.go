@@ -0,0 +1,60 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// AccountResponse is the public-facing representation of an account,
+// including its current balance in the account currency.
+type AccountResponse struct {
+	ID             uint            `json:"id"`
+	UserID         uint            `json:"user_id"`
+	Name           string          `json:"name"`
+	Currency       string          `json:"currency"`
+	OpeningBalance decimal.Decimal `json:"opening_balance" swaggertype:"number"`
+	Archived       bool            `json:"archived"`
+	Balance        decimal.Decimal `json:"balance" swaggertype:"number"`
+}
+
+// AccountCreateRequest is the request body for creating an account.
+type AccountCreateRequest struct {
+	UserID         uint            `json:"user_id"`
+	Name           string          `json:"name" binding:"required,max=64"`
+	Currency       string          `json:"currency"` // defaults to VND
+	OpeningBalance decimal.Decimal `json:"opening_balance" swaggertype:"number"`
+}
+
+// AccountUpdateRequest is the request body for updating an account (all fields optional).
+type AccountUpdateRequest struct {
+	Name           *string          `json:"name,omitempty" binding:"omitempty,max=64"`
+	Currency       *string          `json:"currency,omitempty"`
+	OpeningBalance *decimal.Decimal `json:"opening_balance,omitempty" swaggertype:"number"`
+	Archived       *bool            `json:"archived,omitempty"`
+}
+
+// AccountFilter holds query parameters for listing accounts.
+type AccountFilter struct {
+	UserID          uint `form:"user_id"          json:"user_id"`
+	IncludeArchived bool `form:"include_archived" json:"include_archived"`
+}
+
+// AccountBalanceFilter holds query parameters for the running balance endpoint.
+type AccountBalanceFilter struct {
+	From string `form:"from" json:"from"`
+	To   string `form:"to"   json:"to"`
+}
+
+// AccountBalancePoint is the account balance at the end of a day with activity.
+type AccountBalancePoint struct {
+	Date    string          `json:"date"`
+	Change  decimal.Decimal `json:"change" swaggertype:"number"`
+	Balance decimal.Decimal `json:"balance" swaggertype:"number"`
+}
+
+// AccountBalanceHistory is the running balance of an account over a date range.
+// StartBalance is the balance before the first point (opening balance plus earlier activity).
+type AccountBalanceHistory struct {
+	AccountID    uint                  `json:"account_id"`
+	Currency     string                `json:"currency"`
+	StartBalance decimal.Decimal       `json:"start_balance" swaggertype:"number"`
+	EndBalance   decimal.Decimal       `json:"end_balance" swaggertype:"number"`
+	Points       []AccountBalancePoint `json:"points"`
+}
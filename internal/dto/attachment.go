@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// AttachmentResponse is the public-facing metadata of an expense attachment.
+// The content is downloaded from DownloadURL.
+type AttachmentResponse struct {
+	ID          uint      `json:"id"`
+	ExpenseID   uint      `json:"expense_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	DownloadURL string    `json:"download_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// BudgetResponse is the public-facing representation of a budget.
+type BudgetResponse struct {
+	ID         uint            `json:"id"`
+	UserID     uint            `json:"user_id"`
+	Type       string          `json:"type"` // "" = overall
+	Amount     decimal.Decimal `json:"amount" swaggertype:"number"`
+	Currency   string          `json:"currency"`
+	StartMonth string          `json:"start_month,omitempty"`
+}
+
+// BudgetCreateRequest is the request body for creating a budget.
+type BudgetCreateRequest struct {
+	UserID     uint            `json:"user_id"`
+	Type       string          `json:"type"` // omit for an overall budget
+	Amount     decimal.Decimal `json:"amount" swaggertype:"number"`
+	Currency   string          `json:"currency"`    // defaults to VND
+	StartMonth string          `json:"start_month"` // YYYY-MM
+}
+
+// BudgetUpdateRequest is the request body for updating a budget (all fields optional).
+type BudgetUpdateRequest struct {
+	Amount     *decimal.Decimal `json:"amount,omitempty" swaggertype:"number"`
+	Currency   *string          `json:"currency,omitempty"`
+	StartMonth *string          `json:"start_month,omitempty"`
+}
+
+// BudgetFilter holds query parameters for listing budgets.
+type BudgetFilter struct {
+	UserID uint `form:"user_id" json:"user_id"`
+}
+
+// BudgetStatusFilter holds query parameters for the budget status endpoint.
+type BudgetStatusFilter struct {
+	UserID  uint   `form:"user_id" json:"user_id"`
+	Month   string `form:"month"   json:"month"`   // YYYY-MM, defaults to the current month
+	Periods int    `form:"periods" json:"periods"` // number of months ending at Month (default: 1)
+}
+
+// BudgetPeriodStatus is the progress of a budget within a single month.
+type BudgetPeriodStatus struct {
+	Month       string          `json:"month"`
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Limit       decimal.Decimal `json:"limit" swaggertype:"number"`
+	Spent       decimal.Decimal `json:"spent" swaggertype:"number"`
+	Remaining   decimal.Decimal `json:"remaining" swaggertype:"number"`
+	PercentUsed float64         `json:"percent_used"`
+	OverBudget  bool            `json:"over_budget"`
+}
+
+// BudgetStatus is a budget together with its progress, newest period first.
+type BudgetStatus struct {
+	Budget  BudgetResponse       `json:"budget"`
+	Periods []BudgetPeriodStatus `json:"periods"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+// CategoryResponse is the public-facing representation of a category. Default
+// categories are built in and shared by all users. Children is only filled
+// when the tree view is requested.
+type CategoryResponse struct {
+	ID       uint               `json:"id"`
+	UserID   *uint              `json:"user_id"`
+	ParentID *uint              `json:"parent_id"`
+	Name     string             `json:"name"`
+	Kind     string             `json:"kind"`
+	Icon     string             `json:"icon"`
+	Color    string             `json:"color"`
+	Default  bool               `json:"default"`
+	Children []CategoryResponse `json:"children,omitempty"`
+}
+
+// CategoryCreateRequest is the request body for creating a custom category.
+type CategoryCreateRequest struct {
+	UserID   uint   `json:"user_id"`
+	ParentID *uint  `json:"parent_id"`
+	Name     string `json:"name"  binding:"required,max=32"`
+	Kind     string `json:"kind"  binding:"required,oneof=expense income"`
+	Icon     string `json:"icon"  binding:"max=64"`
+	Color    string `json:"color" binding:"max=16"`
+}
+
+// CategoryUpdateRequest is the request body for updating a category (all fields optional).
+// The kind of a category cannot change, since its expenses would no longer match.
+type CategoryUpdateRequest struct {
+	ParentID *uint   `json:"parent_id,omitempty"` // 0 moves the category to the top level
+	Name     *string `json:"name,omitempty"  binding:"omitempty,max=32"`
+	Icon     *string `json:"icon,omitempty"  binding:"omitempty,max=64"`
+	Color    *string `json:"color,omitempty" binding:"omitempty,max=16"`
+}
+
+// CategoryFilter holds query parameters for listing categories.
+type CategoryFilter struct {
+	UserID uint   `form:"user_id" json:"user_id"`
+	Kind   string `form:"kind"    json:"kind"`
+	Tree   bool   `form:"tree"    json:"tree"`
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CurrencyResponse describes an ISO 4217 currency.
+type CurrencyResponse struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Symbol     string `json:"symbol,omitempty"`
+	MinorUnits int32  `json:"minor_units"` // number of decimals amounts may have
+	Favorite   bool   `json:"favorite"`
+}
+
+// FavoriteCurrenciesRequest replaces the user's favorite currencies. The
+// order of Currencies is the order they are listed in.
+type FavoriteCurrenciesRequest struct {
+	Currencies []string `json:"currencies" binding:"max=20"`
+}
+
+// ExchangeRatesResponse holds the cached exchange rates and their freshness.
+// Stale rates are still served while a refresh runs in the background.
+type ExchangeRatesResponse struct {
+	BaseCurrency string             `json:"base_currency"`
+	Rates        map[string]float64 `json:"rates"` // value of one unit in the base currency
+	Date         string             `json:"date,omitempty"`
+	Source       string             `json:"source"`               // provider name, or "default" before the first fetch
+	UpdatedAt    *time.Time         `json:"updated_at,omitempty"` // last successful refresh
+	AgeSeconds   int64              `json:"age_seconds"`
+	Stale        bool               `json:"stale"`
+	LastError    string             `json:"last_error,omitempty"` // why the last refresh failed
+}
+
+// ConvertQuery holds the query parameters of the conversion endpoint.
+type ConvertQuery struct {
+	Amount string `form:"amount" binding:"required"`
+	From   string `form:"from"   binding:"required,len=3"`
+	To     string `form:"to"     binding:"required,len=3"`
+	Date   string `form:"date"` // YYYY-MM-DD, defaults to today
+}
+
+// ConvertResponse is the result of converting an amount on a date.
+type ConvertResponse struct {
+	Amount    decimal.Decimal `json:"amount" swaggertype:"number"`
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Date      string          `json:"date"`
+	Rate      decimal.Decimal `json:"rate" swaggertype:"number"`      // units of To per unit of From
+	Converted decimal.Decimal `json:"converted" swaggertype:"number"` // rounded to the minor unit of To
+	Source    string          `json:"source"`                         // override, history, live or identity
+}
+
+// RateOverrideRequest is the request body for creating or replacing a manual
+// exchange rate override.
+type RateOverrideRequest struct {
+	FromCurrency string          `json:"from_currency" binding:"required,len=3"`
+	ToCurrency   string          `json:"to_currency"   binding:"required,len=3"`
+	StartDate    string          `json:"start_date"    binding:"required"` // YYYY-MM-DD
+	EndDate      string          `json:"end_date"      binding:"required"` // YYYY-MM-DD, inclusive
+	Rate         decimal.Decimal `json:"rate" swaggertype:"number"`        // units of to_currency per unit of from_currency
+	Note         string          `json:"note" binding:"max=255"`
+}
+
+// RateOverrideResponse is the public-facing representation of a rate override.
+type RateOverrideResponse struct {
+	ID           uint            `json:"id"`
+	FromCurrency string          `json:"from_currency"`
+	ToCurrency   string          `json:"to_currency"`
+	StartDate    string          `json:"start_date"`
+	EndDate      string          `json:"end_date"`
+	Rate         decimal.Decimal `json:"rate" swaggertype:"number"`
+	Note         string          `json:"note,omitempty"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
@@ -1,64 +1,216 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 // ExpenseResponse is the public-facing representation of an expense record.
 type ExpenseResponse struct {
-	ID          uint    `json:"id"`
-	UserID      uint    `json:"user_id"`
-	Amount      float64 `json:"amount"`
-	Currency    string  `json:"currency"`
-	Kind        string  `json:"kind"`
-	Type        string  `json:"type"`
-	Resource    string  `json:"resource"`
-	Description string  `json:"description"`
-	Date        string  `json:"date"`
+	ID              uint                   `json:"id"`
+	UserID          uint                   `json:"user_id"`
+	Amount          decimal.Decimal        `json:"amount" swaggertype:"number"`
+	Currency        string                 `json:"currency"`
+	Kind            string                 `json:"kind"`
+	Type            string                 `json:"type"`
+	CategoryID      *uint                  `json:"category_id"`
+	AccountID       *uint                  `json:"account_id"`
+	Tags            []string               `json:"tags"`
+	Splits          []ExpenseSplitResponse `json:"splits,omitempty"`
+	Description     string                 `json:"description"`
+	Date            string                 `json:"date"`
+	RecurringRuleID *uint                  `json:"recurring_rule_id,omitempty"`
+	TransferID      *uint                  `json:"transfer_id,omitempty"`
+	ExternalID      *string                `json:"external_id,omitempty"`
+	Highlight       string                 `json:"highlight,omitempty"`  // description with search matches wrapped in <mark></mark>
+	DeletedAt       *time.Time             `json:"deleted_at,omitempty"` // set on expenses in the trash
+}
+
+// ExpenseSplitResponse is one line of a split expense.
+type ExpenseSplitResponse struct {
+	ID          uint            `json:"id"`
+	Amount      decimal.Decimal `json:"amount" swaggertype:"number"`
+	Type        string          `json:"type"`
+	CategoryID  *uint           `json:"category_id"`
+	Description string          `json:"description"`
+}
+
+// ExpenseSplitRequest is one line of a split expense. Like the expense itself,
+// the category is derived from the type when not given.
+type ExpenseSplitRequest struct {
+	Amount      decimal.Decimal `json:"amount" swaggertype:"number"`
+	Type        string          `json:"type"`
+	CategoryID  *uint           `json:"category_id"`
+	Description string          `json:"description"`
 }
 
 // ExpenseListResponse is a simple paginated list — no aggregated meta.
 // Use GET /expenses/summary for totals and GET /expenses/groups for time-bucket data.
+// Total counts all matching rows; Count only those in Data. NextCursor fetches
+// the following page and is empty on the last one.
 type ExpenseListResponse struct {
-	Page     int               `json:"page"`
-	PageSize int               `json:"page_size"`
-	Count    int               `json:"count"`
-	Data     []ExpenseResponse `json:"data"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	Count      int               `json:"count"`
+	Total      int64             `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Data       []ExpenseResponse `json:"data"`
 }
 
 // ExpenseCreateRequest is the request body for creating an expense.
 type ExpenseCreateRequest struct {
-	UserID      uint    `json:"user_id"`
-	Amount      float64 `json:"amount"`
-	Currency    string  `json:"currency"`
-	Kind        string  `json:"kind"`
-	Type        string  `json:"type"`
-	Resource    string  `json:"resource"`
-	Description string  `json:"description"`
-	Date        string  `json:"date"`
+	UserID      uint                  `json:"user_id"`
+	Amount      decimal.Decimal       `json:"amount" swaggertype:"number"`
+	Currency    string                `json:"currency"`
+	Kind        string                `json:"kind"`
+	Type        string                `json:"type"`
+	CategoryID  *uint                 `json:"category_id"` // overrides type; derived from type when omitted
+	AccountID   *uint                 `json:"account_id"`
+	Tags        []string              `json:"tags"`
+	Splits      []ExpenseSplitRequest `json:"splits"` // line amounts must add up to amount
+	Description string                `json:"description"`
+	Date        string                `json:"date"`
 }
 
 // ExpenseUpdateRequest is the request body for updating an expense (all fields optional).
 type ExpenseUpdateRequest struct {
-	Amount      *float64 `json:"amount,omitempty"`
-	Currency    *string  `json:"currency,omitempty"`
-	Kind        *string  `json:"kind,omitempty"`
-	Type        *string  `json:"type,omitempty"`
-	Resource    *string  `json:"resource,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Date        *string  `json:"date,omitempty"`
+	Amount      *decimal.Decimal       `json:"amount,omitempty" swaggertype:"number"`
+	Currency    *string                `json:"currency,omitempty"`
+	Kind        *string                `json:"kind,omitempty"`
+	Type        *string                `json:"type,omitempty"`
+	CategoryID  *uint                  `json:"category_id,omitempty"` // 0 derives the category from the type again
+	AccountID   *uint                  `json:"account_id,omitempty"`  // 0 detaches the expense from its account
+	Tags        *[]string              `json:"tags,omitempty"`        // replaces all tags; [] removes them
+	Splits      *[]ExpenseSplitRequest `json:"splits,omitempty"`      // replaces all lines; [] removes the split
+	Description *string                `json:"description,omitempty"`
+	Date        *string                `json:"date,omitempty"`
+}
+
+// ExpenseFieldChange is the value of a field before and after a change; null
+// when the field did not exist, as on create or delete.
+type ExpenseFieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ExpenseRevisionResponse is one entry of the change history of an expense.
+// State holds the tracked fields after the change (before it, for a delete).
+type ExpenseRevisionResponse struct {
+	ID         uint                          `json:"id"`
+	ExpenseID  uint                          `json:"expense_id"`
+	ActorID    *uint                         `json:"actor_id"` // null for changes made by the service, e.g. recurring rules
+	Action     string                        `json:"action"`   // create, update, delete, restore or revert
+	RevertedTo *uint                         `json:"reverted_to,omitempty"`
+	Changes    map[string]ExpenseFieldChange `json:"changes"`
+	State      map[string]interface{}        `json:"state"`
+	CreatedAt  time.Time                     `json:"created_at"`
+}
+
+// Bulk modes. In atomic mode (the default) nothing is saved unless every item
+// succeeds; in best_effort mode the successful items are saved and the failed
+// ones only reported.
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+// ExpenseBulkCreateRequest is the request body for creating several expenses.
+type ExpenseBulkCreateRequest struct {
+	Mode  string                 `json:"mode"` // atomic (default) or best_effort
+	Items []ExpenseCreateRequest `json:"items" binding:"required,min=1,max=500"`
+}
+
+// ExpenseBulkUpdateItem is one item of a bulk update: the expense ID and the
+// fields to change, as in ExpenseUpdateRequest.
+type ExpenseBulkUpdateItem struct {
+	ID uint `json:"id"`
+	ExpenseUpdateRequest
+}
+
+// ExpenseBulkUpdateRequest is the request body for updating several expenses.
+type ExpenseBulkUpdateRequest struct {
+	Mode  string                  `json:"mode"` // atomic (default) or best_effort
+	Items []ExpenseBulkUpdateItem `json:"items" binding:"required,min=1,max=500"`
+}
+
+// ExpenseBulkDeleteRequest is the request body for deleting several expenses.
+type ExpenseBulkDeleteRequest struct {
+	Mode string `json:"mode"` // atomic (default) or best_effort
+	IDs  []uint `json:"ids"  binding:"required,min=1,max=500"`
+}
+
+// ExpenseBulkItemResult is the outcome of one item of a bulk request. Status
+// and Error are what the single-item endpoint would have returned; items of a
+// rolled back atomic request that did not fail themselves get 424.
+type ExpenseBulkItemResult struct {
+	Index   int              `json:"index"`
+	ID      uint             `json:"id,omitempty"`
+	Status  int              `json:"status"`
+	Error   string           `json:"error,omitempty"`
+	Expense *ExpenseResponse `json:"expense,omitempty"`
+}
+
+// ExpenseBulkResult reports what a bulk request did, with one result per item
+// in request order.
+type ExpenseBulkResult struct {
+	Mode       string                  `json:"mode"`
+	Total      int                     `json:"total"`
+	Succeeded  int                     `json:"succeeded"`
+	Failed     int                     `json:"failed"`
+	RolledBack bool                    `json:"rolled_back"`
+	Results    []ExpenseBulkItemResult `json:"results"`
 }
 
 // ExpenseFilter holds query parameters for filtering and ordering the paginated expense list.
 type ExpenseFilter struct {
-	UserID     uint     `form:"user_id"    json:"user_id"`
-	Kind       string   `form:"kind"       json:"kind"`
-	Types      []string `form:"types"      json:"types"`
-	Currencies []string `form:"currencies" json:"currencies"`
-	From       string   `form:"from"       json:"from"`
-	To         string   `form:"to"         json:"to"`
-	OrderBy    string   `form:"order_by"   json:"order_by"`
-	OrderDir   string   `form:"order_dir"  json:"order_dir"`
-	Page       int      `form:"page"       json:"page"`
-	PageSize   int      `form:"page_size"  json:"page_size"`
+	UserID     uint     `form:"user_id"     json:"user_id"`
+	Kind       string   `form:"kind"        json:"kind"`
+	Types      []string `form:"types"       json:"types"`
+	Currencies []string `form:"currencies"  json:"currencies"`
+	AccountIDs []uint   `form:"account_ids" json:"account_ids"`
+	Tags       []string `form:"tags"        json:"tags"`
+	TagsMode   string   `form:"tags_mode"   json:"tags_mode"` // any (default) or all
+	Q          string   `form:"q"           json:"q"`         // full-text search over description and type
+	From       string   `form:"from"        json:"from"`
+	To         string   `form:"to"          json:"to"`
+	OrderBy    string   `form:"order_by"    json:"order_by"`
+	OrderDir   string   `form:"order_dir"   json:"order_dir"`
+	Page       int      `form:"page"        json:"page"`
+	PageSize   int      `form:"page_size"   json:"page_size"`
+	Cursor     string   `form:"cursor"      json:"cursor"` // next_cursor of the previous page; replaces page
+}
+
+// TrashFilter holds query parameters for the list of deleted expenses.
+type TrashFilter struct {
+	UserID   uint `form:"user_id"   json:"user_id"`
+	Page     int  `form:"page"      json:"page"`
+	PageSize int  `form:"page_size" json:"page_size"`
 }
 
+// ExpenseExportOptions holds the export-specific query parameters; the rows
+// are selected with the regular ExpenseFilter parameters.
+type ExpenseExportOptions struct {
+	Format    string `form:"format"`     // csv (default), xlsx or ndjson
+	ConvertTo string `form:"convert_to"` // adds an amount column converted to this currency
+}
+
+// Rate modes of the summary and groups endpoints. In historical mode (the
+// default) each row is converted at the exchange rate of its own date; in
+// current mode at today's rate.
+const (
+	RateModeHistorical = "historical"
+	RateModeCurrent    = "current"
+)
+
+// Comparison windows of the summary endpoint. The previous period is the
+// window of the same length just before from..to (whole calendar months shift
+// by months); the previous year is from..to one year earlier.
+const (
+	ComparePreviousPeriod = "previous_period"
+	ComparePreviousYear   = "previous_year"
+)
+
 // SummaryFilter holds query parameters for the summary endpoint.
 // It supports the same field filters as ExpenseFilter so totals reflect filtered data.
 type SummaryFilter struct {
@@ -66,7 +218,13 @@ type SummaryFilter struct {
 	Kind             string   `form:"kind"              json:"kind"`
 	Types            []string `form:"types"             json:"types"`
 	Currencies       []string `form:"currencies"        json:"currencies"`
+	AccountIDs       []uint   `form:"account_ids"       json:"account_ids"`
+	Tags             []string `form:"tags"              json:"tags"`
+	TagsMode         string   `form:"tags_mode"         json:"tags_mode"` // any (default) or all
+	Q                string   `form:"q"                 json:"q"`         // full-text search over description and type
 	OriginalCurrency string   `form:"original_currency" json:"original_currency"`
+	RateMode         string   `form:"rate_mode"         json:"rate_mode" binding:"omitempty,oneof=historical current"`
+	Compare          string   `form:"compare"           json:"compare" binding:"omitempty,oneof=previous_period previous_year"` // needs from and to
 	From             string   `form:"from"              json:"from"`
 	To               string   `form:"to"                json:"to"`
 }
@@ -77,7 +235,12 @@ type GroupsFilter struct {
 	Kind             string   `form:"kind"              json:"kind"`
 	Types            []string `form:"types"             json:"types"`
 	Currencies       []string `form:"currencies"        json:"currencies"`
+	AccountIDs       []uint   `form:"account_ids"       json:"account_ids"`
+	Tags             []string `form:"tags"              json:"tags"`
+	TagsMode         string   `form:"tags_mode"         json:"tags_mode"` // any (default) or all
+	Q                string   `form:"q"                 json:"q"`         // full-text search over description and type
 	OriginalCurrency string   `form:"original_currency" json:"original_currency"`
+	RateMode         string   `form:"rate_mode"         json:"rate_mode" binding:"omitempty,oneof=historical current"`
 	From             string   `form:"from"              json:"from"`
 	To               string   `form:"to"                json:"to"`
 	GroupBy          string   `form:"group_by"          json:"group_by"`
@@ -88,24 +251,32 @@ type GroupsFilter struct {
 }
 
 // CurrencySummary holds per-native-currency income/expense/balance totals.
+// TotalTransfer is the net of transfer legs; it is part of TotalBalance but
+// never of TotalIncome or TotalExpense.
 type CurrencySummary struct {
-	TotalIncome  float64 `json:"total_income"`
-	TotalExpense float64 `json:"total_expense"`
-	TotalBalance float64 `json:"total_balance"`
+	TotalIncome   decimal.Decimal `json:"total_income" swaggertype:"number"`
+	TotalExpense  decimal.Decimal `json:"total_expense" swaggertype:"number"`
+	TotalTransfer decimal.Decimal `json:"total_transfer" swaggertype:"number"`
+	TotalBalance  decimal.Decimal `json:"total_balance" swaggertype:"number"`
 }
 
 // ExpenseGroup represents aggregated totals for a time bucket (day/week/month/year).
+// TotalByCategory is keyed by the leaf category of each expense and
+// TotalByParentCategory rolls those totals up to the top-level category.
 type ExpenseGroup struct {
-	Key         string             `json:"key"`
-	Label       string             `json:"label"`
-	Income      float64            `json:"income"`
-	Expense     float64            `json:"expense"`
-	Balance     float64            `json:"balance"`
-	TotalByType map[string]float64 `json:"total_by_type"`
+	Key                   string                     `json:"key"`
+	Label                 string                     `json:"label"`
+	Income                decimal.Decimal            `json:"income" swaggertype:"number"`
+	Expense               decimal.Decimal            `json:"expense" swaggertype:"number"`
+	Balance               decimal.Decimal            `json:"balance" swaggertype:"number"`
+	TotalByType           map[string]decimal.Decimal `json:"total_by_type" swaggertype:"object,number"`
+	TotalByCategory       map[uint]decimal.Decimal   `json:"total_by_category" swaggertype:"object,number"`
+	TotalByParentCategory map[uint]decimal.Decimal   `json:"total_by_parent_category" swaggertype:"object,number"`
 }
 
 // ExpenseGroupsResponse is the paginated response from GET /expenses/groups.
 type ExpenseGroupsResponse struct {
+	RateMode string         `json:"rate_mode"`
 	Total    int            `json:"total"`
 	Page     int            `json:"page"`
 	PageSize int            `json:"page_size"`
@@ -113,15 +284,49 @@ type ExpenseGroupsResponse struct {
 }
 
 // ExpenseSummary is the response from GET /expenses/summary.
-// Groups have been moved to GET /expenses/groups.
+// Groups have been moved to GET /expenses/groups. Category totals are keyed the
+// same way as in ExpenseGroup.
 type ExpenseSummary struct {
-	Currency           string                      `json:"currency"`
-	IncomeCount        int                         `json:"income_count"`
-	ExpenseCount       int                         `json:"expense_count"`
-	TotalIncome        float64                     `json:"total_income"`
-	TotalExpense       float64                     `json:"total_expense"`
-	TotalBalance       float64                     `json:"total_balance"`
-	TotalByTypeIncome  map[string]float64          `json:"total_by_type_income"`
-	TotalByTypeExpense map[string]float64          `json:"total_by_type_expense"`
-	ByCurrency         map[string]*CurrencySummary `json:"by_currency,omitempty"`
+	Currency              string                      `json:"currency"`
+	RateMode              string                      `json:"rate_mode"`
+	IncomeCount           int                         `json:"income_count"`
+	ExpenseCount          int                         `json:"expense_count"`
+	TransferCount         int                         `json:"transfer_count"`
+	TotalIncome           decimal.Decimal             `json:"total_income" swaggertype:"number"`
+	TotalExpense          decimal.Decimal             `json:"total_expense" swaggertype:"number"`
+	TotalBalance          decimal.Decimal             `json:"total_balance" swaggertype:"number"`
+	TotalByTypeIncome     map[string]decimal.Decimal  `json:"total_by_type_income" swaggertype:"object,number"`
+	TotalByTypeExpense    map[string]decimal.Decimal  `json:"total_by_type_expense" swaggertype:"object,number"`
+	TotalByCategory       map[uint]decimal.Decimal    `json:"total_by_category" swaggertype:"object,number"`
+	TotalByParentCategory map[uint]decimal.Decimal    `json:"total_by_parent_category" swaggertype:"object,number"`
+	ByCurrency            map[string]*CurrencySummary `json:"by_currency,omitempty"`
+	ByAccount             map[uint]*CurrencySummary   `json:"by_account,omitempty"` // converted to Currency; includes transfers
+	Comparison            *SummaryComparison          `json:"comparison,omitempty"` // only with compare
+}
+
+// SummaryComparison compares a summary with the same summary over an earlier
+// window.
+type SummaryComparison struct {
+	Compare            string                  `json:"compare"`
+	From               string                  `json:"from"`
+	To                 string                  `json:"to"`
+	Previous           *ExpenseSummary         `json:"previous"`
+	TotalIncome        SummaryDelta            `json:"total_income"`
+	TotalExpense       SummaryDelta            `json:"total_expense"`
+	TotalBalance       SummaryDelta            `json:"total_balance"`
+	TotalByTypeIncome  map[string]SummaryDelta `json:"total_by_type_income"`
+	TotalByTypeExpense map[string]SummaryDelta `json:"total_by_type_expense"`
+}
+
+// SummaryDelta is the change of one total from the previous window. Percent
+// is null when Previous is 0. For income and expense totals, whose sign is
+// fixed, it is Current/Previous - 1 in percent, so a larger expense total
+// (more negative) is positive, e.g. 18 for "+18%". For the balance, which can
+// change sign, it is Change/|Previous| in percent, positive when the balance
+// went up.
+type SummaryDelta struct {
+	Current  decimal.Decimal `json:"current" swaggertype:"number"`
+	Previous decimal.Decimal `json:"previous" swaggertype:"number"`
+	Change   decimal.Decimal `json:"change" swaggertype:"number"` // Current - Previous
+	Percent  *float64        `json:"percent"`
 }
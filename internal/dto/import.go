@@ -0,0 +1,57 @@
+package dto
+
+// ExpenseImportRequest holds the multipart form fields of an import upload
+// (the file itself is sent as "file").
+type ExpenseImportRequest struct {
+	UserID           uint   `form:"user_id"`
+	Mapping          string `form:"mapping"`           // JSON object: field -> column header or 1-based column number
+	DateFormat       string `form:"date_format"`       // e.g. DD/MM/YYYY, default YYYY-MM-DD (MM/DD/YYYY for QIF)
+	DecimalSeparator string `form:"decimal_separator"` // "." (default) or ","
+	Format           string `form:"format"`            // csv, ofx (also qfx), qif or statement; taken from the file extension when empty
+	Bank             string `form:"bank"`              // statement parser (vcb, vpbank, bidv, cake); detected when empty
+	Delimiter        string `form:"delimiter"`         // detected from the header line when empty
+	Currency         string `form:"currency"`          // used for rows without a currency column value, default VND
+	AccountID        *uint  `form:"account_id"`        // used for rows without an account column value; overrides the bank account of statements
+	DryRun           bool   `form:"dry_run"`
+}
+
+// ExpenseImportRow is the outcome for one line of the uploaded file.
+// Duplicate rows were imported before (same external ID) and are skipped.
+type ExpenseImportRow struct {
+	Line      int             `json:"line"`
+	Expense   ExpenseResponse `json:"expense"`
+	Duplicate bool            `json:"duplicate,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// ExpenseImportResult reports what an import did, or would do in dry-run mode.
+// Nothing is written unless every row is valid.
+type ExpenseImportResult struct {
+	DryRun     bool               `json:"dry_run"`
+	Total      int                `json:"total"`
+	Valid      int                `json:"valid"`
+	Invalid    int                `json:"invalid"`
+	Duplicates int                `json:"duplicates"`
+	Imported   int                `json:"imported"`
+	Rows       []ExpenseImportRow `json:"rows"`
+}
+
+// NotificationParseRequest is the request body for turning a bank SMS or push
+// notification into an expense.
+type NotificationParseRequest struct {
+	UserID    uint   `json:"user_id"`
+	Text      string `json:"text" binding:"required"`
+	Bank      string `json:"bank"`       // vcb, bidv, vpbank or cake; detected from the text when empty
+	Type      string `json:"type"`       // expense type for the draft
+	AccountID *uint  `json:"account_id"` // defaults to the account named after the bank
+	Create    bool   `json:"create"`     // create the expense instead of only returning the draft
+}
+
+// NotificationParseResponse holds the draft extracted from a notification and,
+// when it was created, the resulting expense.
+type NotificationParseResponse struct {
+	Bank    string               `json:"bank,omitempty"`
+	Time    string               `json:"time,omitempty"`
+	Draft   ExpenseCreateRequest `json:"draft"`
+	Expense *ExpenseResponse     `json:"expense,omitempty"`
+}
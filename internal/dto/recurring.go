@@ -0,0 +1,59 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// RecurringRuleResponse is the public-facing representation of a recurring rule.
+type RecurringRuleResponse struct {
+	ID              uint            `json:"id"`
+	UserID          uint            `json:"user_id"`
+	Amount          decimal.Decimal `json:"amount" swaggertype:"number"`
+	Currency        string          `json:"currency"`
+	Kind            string          `json:"kind"`
+	Type            string          `json:"type"`
+	AccountID       *uint           `json:"account_id"`
+	Description     string          `json:"description"`
+	Frequency       string          `json:"frequency"`
+	Interval        int             `json:"interval"`
+	StartDate       string          `json:"start_date"`
+	EndDate         string          `json:"end_date,omitempty"`
+	NextRun         string          `json:"next_run,omitempty"`
+	OccurrenceCount int             `json:"occurrence_count"`
+	Paused          bool            `json:"paused"`
+}
+
+// RecurringRuleCreateRequest is the request body for creating a recurring rule.
+// The template fields mirror ExpenseCreateRequest.
+type RecurringRuleCreateRequest struct {
+	UserID      uint            `json:"user_id"`
+	Amount      decimal.Decimal `json:"amount" swaggertype:"number"`
+	Currency    string          `json:"currency"`
+	Kind        string          `json:"kind"`
+	Type        string          `json:"type"`
+	AccountID   *uint           `json:"account_id"`
+	Description string          `json:"description"`
+	Frequency   string          `json:"frequency" binding:"required,oneof=DAILY WEEKLY MONTHLY YEARLY"`
+	Interval    int             `json:"interval"`   // defaults to 1
+	StartDate   string          `json:"start_date"` // defaults to today
+	EndDate     string          `json:"end_date,omitempty"`
+	Paused      bool            `json:"paused"`
+}
+
+// RecurringRuleUpdateRequest is the request body for updating a recurring rule (all fields optional).
+type RecurringRuleUpdateRequest struct {
+	Amount      *decimal.Decimal `json:"amount,omitempty" swaggertype:"number"`
+	Currency    *string          `json:"currency,omitempty"`
+	Kind        *string          `json:"kind,omitempty"`
+	Type        *string          `json:"type,omitempty"`
+	AccountID   *uint            `json:"account_id,omitempty"` // 0 detaches the rule from its account
+	Description *string          `json:"description,omitempty"`
+	Frequency   *string          `json:"frequency,omitempty" binding:"omitempty,oneof=DAILY WEEKLY MONTHLY YEARLY"`
+	Interval    *int             `json:"interval,omitempty"`
+	StartDate   *string          `json:"start_date,omitempty"`
+	EndDate     *string          `json:"end_date,omitempty"` // "" clears the end date
+	Paused      *bool            `json:"paused,omitempty"`
+}
+
+// RecurringRuleFilter holds query parameters for listing recurring rules.
+type RecurringRuleFilter struct {
+	UserID uint `form:"user_id" json:"user_id"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// TransferCreateRequest is the request body for moving money between two accounts.
+// Amount is taken out of the source account; ToAmount is put into the destination
+// account and only needs to be set when the two sides use different currencies
+// (it defaults to Amount converted at the current exchange rate).
+type TransferCreateRequest struct {
+	UserID        uint            `json:"user_id"`
+	FromAccountID uint            `json:"from_account_id" binding:"required"`
+	ToAccountID   uint            `json:"to_account_id"   binding:"required"`
+	Amount        decimal.Decimal `json:"amount"          swaggertype:"number"`
+	Currency      string          `json:"currency"`                       // defaults to the source account currency
+	ToAmount      decimal.Decimal `json:"to_amount" swaggertype:"number"` // optional, positive
+	ToCurrency    string          `json:"to_currency"`                    // defaults to the destination account currency
+	Description   string          `json:"description"`
+	Date          string          `json:"date"`
+}
+
+// TransferResponse is the public-facing representation of a transfer and its two legs.
+type TransferResponse struct {
+	ID            uint            `json:"id"`
+	UserID        uint            `json:"user_id"`
+	FromAccountID uint            `json:"from_account_id"`
+	ToAccountID   uint            `json:"to_account_id"`
+	Description   string          `json:"description"`
+	Date          string          `json:"date"`
+	Debit         ExpenseResponse `json:"debit"`
+	Credit        ExpenseResponse `json:"credit"`
+}
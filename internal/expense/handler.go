@@ -1,6 +1,10 @@
 package expense
 
 import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +14,8 @@ import (
 	"mindoh-service/internal/auth"
 	dbmodel "mindoh-service/internal/db"
 	"mindoh-service/internal/dto"
+	"mindoh-service/internal/exporter"
+	"mindoh-service/internal/importer"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,31 +29,32 @@ func NewExpenseHandler(service *ExpenseService) *ExpenseHandler {
 	return &ExpenseHandler{Service: service}
 }
 
-// AddExpense godoc
-// @Summary Add a new expense
-// @Description Create a new expense record
-// @Tags expenses
-// @Accept json
-// @Produce json
-// @Param expense body dto.ExpenseCreateRequest true "Expense details"
-// @Success 201 {object} dto.ExpenseResponse "Expense created successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request"
-// @Failure 403 {object} map[string]interface{} "Forbidden"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Security BearerAuth
-// @Router /expenses [post]
-func (h *ExpenseHandler) AddExpense(c *gin.Context) {
-	var req dto.ExpenseCreateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+// requestError is a failed create, update or delete with the HTTP status to
+// report, so the single and bulk endpoints share the same checks.
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string { return e.message }
+
+// respondError writes err as the JSON error response.
+func respondError(c *gin.Context, err error) {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		c.JSON(reqErr.status, gin.H{"error": reqErr.message})
 		return
 	}
-	authCtx := auth.GetAuthContext(c)
+	slog.Error("expense request failed", "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
+
+// createExpense checks that the caller may create req and saves it through s.
+func createExpense(s *ExpenseService, authCtx auth.AuthContext, req dto.ExpenseCreateRequest) (*dbmodel.Expense, error) {
 	userID := authCtx.UserID
 	role := authCtx.Role
 	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own expenses"})
-		return
+		return nil, &requestError{http.StatusForbidden, "You can only add your own expenses"}
 	}
 	if role == auth.RoleUser || req.UserID == 0 {
 		req.UserID = userID
@@ -58,62 +65,40 @@ func (h *ExpenseHandler) AddExpense(c *gin.Context) {
 	}
 	// Validate date format
 	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
-		return
+		return nil, &requestError{http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD"}
+	}
+	if req.Currency == "" {
+		req.Currency = "VND"
 	}
 	expense := dbmodel.Expense{
 		UserID:      req.UserID,
 		Amount:      req.Amount,
-		Currency:    req.Currency,
+		Currency:    strings.ToUpper(req.Currency),
 		Kind:        dbmodel.ExpenseKind(req.Kind),
 		Type:        strings.ToLower(strings.TrimSpace(req.Type)),
-		Resource:    dbmodel.ExpenseResource(req.Resource),
+		CategoryID:  req.CategoryID,
+		AccountID:   req.AccountID,
+		Tags:        toTagModels(req.Tags),
+		Splits:      toSplitModels(req.Splits),
 		Description: req.Description,
 		Date:        req.Date,
 	}
-	if err := h.Service.AddExpense(&expense); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := s.AddExpense(&expense, authCtx.UserID); err != nil {
+		return nil, &requestError{http.StatusBadRequest, err.Error()}
 	}
-	c.JSON(http.StatusCreated, toExpenseResponse(&expense))
+	return &expense, nil
 }
 
-// UpdateExpense godoc
-// @Summary Update an existing expense
-// @Description Update details of an existing expense
-// @Tags expenses
-// @Accept json
-// @Produce json
-// @Param id path int true "Expense ID"
-// @Param expense body dto.ExpenseUpdateRequest true "Expense update details"
-// @Success 200 {object} dto.ExpenseResponse "Expense updated successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request"
-// @Failure 403 {object} map[string]interface{} "Forbidden"
-// @Failure 404 {object} map[string]interface{} "Expense not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Security BearerAuth
-// @Router /expenses/{id} [put]
-func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
-	var req dto.ExpenseUpdateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-	authCtx := auth.GetAuthContext(c)
-	userID := authCtx.UserID
-	expenseID := utils.ParseUint(c.Param("id"))
-
+// updateExpense checks that the caller owns expense id and applies the fields
+// given in req through s.
+func updateExpense(s *ExpenseService, authCtx auth.AuthContext, id uint, req dto.ExpenseUpdateRequest) (*dbmodel.Expense, error) {
 	// Fetch existing expense to check ownership
-	expense, err := h.Service.Repo.GetByID(expenseID)
+	expense, err := s.Repo.GetByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
-		return
+		return nil, &requestError{http.StatusNotFound, "Expense not found"}
 	}
-
-	role := authCtx.Role
-	if role == auth.RoleUser && expense.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own expenses"})
-		return
+	if authCtx.Role == auth.RoleUser && expense.UserID != authCtx.UserID {
+		return nil, &requestError{http.StatusForbidden, "You can only add your own expenses"}
 	}
 
 	// Build map of only provided fields; also apply to in-memory object for validation
@@ -127,17 +112,35 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 		fields["kind"] = string(*req.Kind)
 	}
 	if req.Currency != nil {
-		expense.Currency = *req.Currency
-		fields["currency"] = *req.Currency
+		expense.Currency = strings.ToUpper(*req.Currency)
+		fields["currency"] = expense.Currency
 	}
 	if req.Type != nil {
 		normalized := strings.ToLower(strings.TrimSpace(*req.Type))
 		expense.Type = normalized
 		fields["type"] = normalized
 	}
-	if req.Resource != nil {
-		expense.Resource = dbmodel.ExpenseResource(*req.Resource)
-		fields["resource"] = string(*req.Resource)
+	if req.CategoryID != nil {
+		if *req.CategoryID == 0 {
+			expense.CategoryID = nil
+		} else {
+			expense.CategoryID = req.CategoryID
+		}
+		fields["category_id"] = expense.CategoryID
+	}
+	if req.AccountID != nil {
+		if *req.AccountID == 0 {
+			expense.AccountID = nil
+		} else {
+			expense.AccountID = req.AccountID
+		}
+		fields["account_id"] = expense.AccountID
+	}
+	if req.Tags != nil {
+		fields["tags"] = *req.Tags
+	}
+	if req.Splits != nil {
+		fields["splits"] = toSplitModels(*req.Splits)
 	}
 	if req.Description != nil {
 		expense.Description = *req.Description
@@ -145,18 +148,154 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	}
 	if req.Date != nil {
 		if _, err := time.Parse("2006-01-02", *req.Date); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
-			return
+			return nil, &requestError{http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD"}
 		}
 		expense.Date = *req.Date
 		fields["date"] = *req.Date
 	}
 	if len(fields) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return nil, &requestError{http.StatusBadRequest, "No fields to update"}
+	}
+
+	if err := s.UpdateExpenseFields(expense, fields, authCtx.UserID); err != nil {
+		return nil, &requestError{http.StatusBadRequest, err.Error()}
+	}
+	return expense, nil
+}
+
+// deleteExpense checks that the caller owns expense id and deletes it through s.
+func deleteExpense(s *ExpenseService, authCtx auth.AuthContext, id uint) error {
+	// Check if expense exists and belongs to user
+	expense, err := s.GetExpenseByID(id)
+	if err != nil {
+		return &requestError{http.StatusNotFound, "Expense not found"}
+	}
+	if authCtx.Role == auth.RoleUser && expense.UserID != authCtx.UserID {
+		return &requestError{http.StatusForbidden, "You can only delete your own expenses"}
+	}
+	if err := s.DeleteExpense(id, authCtx.UserID); err != nil {
+		return &requestError{http.StatusInternalServerError, "Failed to delete expense"}
+	}
+	return nil
+}
+
+// AddExpense godoc
+// @Summary Add a new expense
+// @Description Create a new expense record
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param expense body dto.ExpenseCreateRequest true "Expense details"
+// @Success 201 {object} dto.ExpenseResponse "Expense created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses [post]
+func (h *ExpenseHandler) AddExpense(c *gin.Context) {
+	var req dto.ExpenseCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	expense, err := createExpense(h.Service, auth.GetAuthContext(c), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toExpenseResponse(expense))
+}
+
+// UpdateExpense godoc
+// @Summary Update an existing expense
+// @Description Update details of an existing expense
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param expense body dto.ExpenseUpdateRequest true "Expense update details"
+// @Success 200 {object} dto.ExpenseResponse "Expense updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id} [put]
+func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
+	var req dto.ExpenseUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	expense, err := updateExpense(h.Service, auth.GetAuthContext(c), utils.ParseUint(c.Param("id")), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toExpenseResponse(expense))
+}
+
+// ExpenseHistory godoc
+// @Summary Get the change history of an expense
+// @Description Get every recorded change of an expense, oldest first: who made it, when, the old and new value of each changed field and the resulting state. Deleted expenses keep their history until purged.
+// @Tags expenses
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Success 200 {array} dto.ExpenseRevisionResponse "Revisions"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id}/history [get]
+func (h *ExpenseHandler) ExpenseHistory(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	expense, err := h.Service.GetExpenseWithDeleted(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && expense.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own expenses"})
+		return
+	}
+	revisions, err := h.Service.ListRevisions(expense.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expense history"})
 		return
 	}
+	c.JSON(http.StatusOK, toRevisionResponseList(revisions))
+}
 
-	if err := h.Service.UpdateExpenseFields(expense, fields); err != nil {
+// RevertExpense godoc
+// @Summary Revert an expense to an earlier revision
+// @Description Set every tracked field (amount, currency, kind, type, category, account, tags, splits, description, date) back to its value at the given revision. The values are validated like an update and the revert is recorded as a new revision.
+// @Tags expenses
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Param revision_id path int true "Revision ID"
+// @Success 200 {object} dto.ExpenseResponse "Expense reverted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense or revision not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/{id}/history/{revision_id}/revert [post]
+func (h *ExpenseHandler) RevertExpense(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	expense, err := h.Service.GetExpenseByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && expense.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own expenses"})
+		return
+	}
+	if err := h.Service.RevertExpense(expense, utils.ParseUint(c.Param("revision_id")), authCtx.UserID); err != nil {
+		if errors.Is(err, ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -165,17 +304,24 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 
 // ListExpenses godoc
 // @Summary List expenses
-// @Description Get list of expenses with optional filtering and ordering
+// @Description Get list of expenses with optional filtering and ordering. Total is the number of matching expenses; pass next_cursor back as cursor to fetch the following page. With q, each match carries the description with the matching words highlighted.
 // @Tags expenses
 // @Accept json
 // @Produce json
 // @Param user_id query int false "User ID"
-// @Param kind query string false "Expense kind (expense/income)"
+// @Param kind query string false "Expense kind (expense/income/transfer)"
 // @Param types query []string false "Expense types filter (food/salary/transport/entertainment) - accepts multiple"
+// @Param account_ids query []int false "Filter by account IDs"
+// @Param tags query []string false "Filter by tags"
+// @Param tags_mode query string false "Tag matching: any (default) or all"
+// @Param q query string false "Search description and type; every word must match the start of a word"
 // @Param from query string false "Start date (YYYY-MM-DD)"
 // @Param to query string false "End date (YYYY-MM-DD)"
 // @Param order_by query string false "Column to order by: date, amount, type, kind, currency, created_at (default: date)"
 // @Param order_dir query string false "Order direction: asc or desc (default: desc)"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size; 0 returns every match unless a cursor is given (default: 25)"
+// @Param cursor query string false "next_cursor of the previous response; stable while rows are inserted, unlike page"
 // @Success 200 {object} dto.ExpenseListResponse "List of expenses with count"
 // @Failure 400 {object} map[string]interface{} "Invalid query parameters"
 // @Failure 403 {object} map[string]interface{} "Forbidden"
@@ -199,42 +345,179 @@ func (h *ExpenseHandler) ListExpenses(c *gin.Context) {
 		filter.UserID = userID
 	}
 
-	expenses, err := h.Service.ListExpenses(filter)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expenses"})
-		return
-	}
-
 	page := filter.Page
-	if page < 1 {
+	if page < 1 || filter.Cursor != "" {
 		page = 1
 	}
 	pageSize := filter.PageSize
 	if pageSize <= 0 {
 		pageSize = 25
 	}
+	// Without a page_size the repository returns every row; a cursor always
+	// pages, so apply the default explicitly.
+	if filter.Cursor != "" && filter.PageSize <= 0 {
+		filter.PageSize = pageSize
+	}
+
+	expenses, nextCursor, err := h.Service.ListExpenses(filter)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expenses"})
+		return
+	}
+	total, err := h.Service.CountExpenses(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count expenses"})
+		return
+	}
+
+	data := toExpenseResponseList(expenses)
+	if filter.Q != "" {
+		highlights, err := h.Service.Highlights(expenses, filter.Q)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to highlight search matches"})
+			return
+		}
+		for i := range data {
+			data[i].Highlight = highlights[data[i].ID]
+		}
+	}
 
 	c.JSON(http.StatusOK, dto.ExpenseListResponse{
-		Page:     page,
-		PageSize: pageSize,
-		Count:    len(expenses),
-		Data:     toExpenseResponseList(expenses),
+		Page:       page,
+		PageSize:   pageSize,
+		Count:      len(expenses),
+		Total:      total,
+		NextCursor: nextCursor,
+		Data:       data,
+	})
+}
+
+// ExportExpenses godoc
+// @Summary Export expenses
+// @Description Download every expense matching the filter (pagination is ignored) as CSV, XLSX or NDJSON. With convert_to, an extra column holds the amount converted at the current exchange rate.
+// @Tags expenses
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param user_id query int false "User ID"
+// @Param kind query string false "Expense kind (expense/income/transfer)"
+// @Param types query []string false "Filter by types"
+// @Param currencies query []string false "Filter by currencies"
+// @Param account_ids query []int false "Filter by account IDs"
+// @Param tags query []string false "Filter by tags"
+// @Param tags_mode query string false "Tag matching: any (default) or all"
+// @Param q query string false "Search description and type; every word must match the start of a word"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param order_by query string false "Column to order by: date, amount, type, kind, currency, created_at (default: date)"
+// @Param order_dir query string false "Order direction: asc or desc (default: desc)"
+// @Param format query string false "File format: csv (default), xlsx or ndjson"
+// @Param convert_to query string false "Add an amount column converted to this currency"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/export [get]
+func (h *ExpenseHandler) ExportExpenses(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.ExpenseFilter
+	var opts dto.ExpenseExportOptions
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only export your own expenses"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = exporter.FormatCSV
+	}
+	if format != exporter.FormatCSV && format != exporter.FormatXLSX && format != exporter.FormatNDJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format, expected csv, xlsx or ndjson"})
+		return
+	}
+
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	filename := "expenses"
+	if filter.From != "" {
+		filename += "_" + filter.From
+	}
+	if filter.To != "" {
+		filename += "_" + filter.To
+	}
+	if filter.From == "" && filter.To == "" {
+		filename += "_" + time.Now().Format("2006-01-02")
+	}
+	filename += "." + format
+
+	started := false
+	err := h.Service.ExportExpenses(filter, strings.ToUpper(opts.ConvertTo), func(columns []string) (exporter.Writer, error) {
+		c.Header("Content-Type", exporter.ContentType(format))
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		c.Status(http.StatusOK)
+		started = true
+		return exporter.NewWriter(format, c.Writer, columns)
 	})
+	if err == nil {
+		return
+	}
+	if started {
+		// Headers are already sent; the client sees a truncated file.
+		slog.Error("expense export failed", "user_id", filter.UserID, "error", err)
+		c.Abort()
+		return
+	}
+	if errors.Is(err, ErrUnknownCurrency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export expenses"})
 }
 
 // Summary godoc
 // @Summary Get expense summary
-// @Description Get totals (income, expense, balance) for a filtered set of records.
+// @Description Get totals (income, expense, balance) for a filtered set of records. With compare, the same summary is computed for the previous period (the window of equal length before from..to; whole calendar months shift by months) or the same window a year earlier, and comparison holds it with the absolute and percentage change of the overall and per-type totals.
 // @Tags expenses
 // @Accept json
 // @Produce json
 // @Param user_id query int false "User ID"
-// @Param kind query string false "Filter by kind (expense/income)"
+// @Param kind query string false "Filter by kind (expense/income/transfer)"
 // @Param types query []string false "Filter by types"
 // @Param currencies query []string false "Filter by currencies"
+// @Param account_ids query []int false "Filter by account IDs"
+// @Param tags query []string false "Filter by tags"
+// @Param tags_mode query string false "Tag matching: any (default) or all"
+// @Param q query string false "Search description and type; every word must match the start of a word"
 // @Param original_currency query string false "Currency to express totals in (default: VND)"
+// @Param rate_mode query string false "Convert at the rate of each row's date (historical, default) or today's rate (current)"
 // @Param from query string false "Start date (YYYY-MM-DD)"
 // @Param to query string false "End date (YYYY-MM-DD)"
+// @Param compare query string false "Compare with previous_period or previous_year (needs from and to)"
 // @Success 200 {object} dto.ExpenseSummary "Expense summary"
 // @Failure 400 {object} map[string]interface{} "Invalid query parameters"
 // @Failure 403 {object} map[string]interface{} "Forbidden"
@@ -259,6 +542,10 @@ func (h *ExpenseHandler) Summary(c *gin.Context) {
 	}
 
 	summary, err := h.Service.Summary(filter)
+	if errors.Is(err, ErrInvalidComparison) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch summary"})
 		return
@@ -273,10 +560,15 @@ func (h *ExpenseHandler) Summary(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user_id query int false "User ID"
-// @Param kind query string false "Filter by kind (expense/income)"
+// @Param kind query string false "Filter by kind (expense/income/transfer)"
 // @Param types query []string false "Filter by types"
 // @Param currencies query []string false "Filter by currencies"
+// @Param account_ids query []int false "Filter by account IDs"
+// @Param tags query []string false "Filter by tags"
+// @Param tags_mode query string false "Tag matching: any (default) or all"
+// @Param q query string false "Search description and type; every word must match the start of a word"
 // @Param original_currency query string false "Currency to express totals in (default: VND)"
+// @Param rate_mode query string false "Convert at the rate of each row's date (historical, default) or today's rate (current)"
 // @Param from query string false "Start date (YYYY-MM-DD)"
 // @Param to query string false "End date (YYYY-MM-DD)"
 // @Param group_by query string true "Bucket size: DAY, WEEK, MONTH or YEAR"
@@ -317,7 +609,7 @@ func (h *ExpenseHandler) Groups(c *gin.Context) {
 
 // DeleteExpense godoc
 // @Summary Delete expense
-// @Description Delete an expense by ID (user can only delete their own expenses)
+// @Description Delete an expense by ID (user can only delete their own expenses). Deleting a transfer leg deletes the whole transfer.
 // @Tags expenses
 // @Accept json
 // @Produce json
@@ -330,35 +622,621 @@ func (h *ExpenseHandler) Groups(c *gin.Context) {
 // @Security BearerAuth
 // @Router /expenses/{id} [delete]
 func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
-	authCtx := auth.GetAuthContext(c)
-	userID := authCtx.UserID
-
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID"})
 		return
 	}
-
-	// Check if expense exists and belongs to user
-	expense, err := h.Service.GetExpenseByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
-		return
-	}
-
-	role := authCtx.Role
-	if role == auth.RoleUser && expense.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own expenses"})
+	if err := deleteExpense(h.Service, auth.GetAuthContext(c), uint(id)); err != nil {
+		respondError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "Expense deleted successfully"})
+}
 
-	// Delete expense
-	if err := h.Service.DeleteExpense(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete expense"})
-		return
+// BulkCreateExpenses godoc
+// @Summary Create several expenses
+// @Description Create up to 500 expenses in one transaction, each checked like a single create. In atomic mode (default) nothing is saved when any item fails; in best_effort mode the valid items are saved. Every item gets its own status and error.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param request body dto.ExpenseBulkCreateRequest true "Expenses to create"
+// @Success 201 {object} dto.ExpenseBulkResult "Items processed"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 422 {object} dto.ExpenseBulkResult "An item failed; nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/bulk [post]
+func (h *ExpenseHandler) BulkCreateExpenses(c *gin.Context) {
+	var req dto.ExpenseBulkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
 	}
+	authCtx := auth.GetAuthContext(c)
+	h.runBulk(c, req.Mode, len(req.Items), http.StatusCreated, func(s *ExpenseService, i int) (uint, *dbmodel.Expense, error) {
+		expense, err := createExpense(s, authCtx, req.Items[i])
+		return 0, expense, err
+	})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Expense deleted successfully"})
+// BulkUpdateExpenses godoc
+// @Summary Update several expenses
+// @Description Apply up to 500 partial updates in one transaction, each checked like a single update. In atomic mode (default) nothing is saved when any item fails; in best_effort mode the valid items are saved. Every item gets its own status and error.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param request body dto.ExpenseBulkUpdateRequest true "Expense IDs with the fields to update"
+// @Success 200 {object} dto.ExpenseBulkResult "Items processed"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 422 {object} dto.ExpenseBulkResult "An item failed; nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/bulk [patch]
+func (h *ExpenseHandler) BulkUpdateExpenses(c *gin.Context) {
+	var req dto.ExpenseBulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	h.runBulk(c, req.Mode, len(req.Items), http.StatusOK, func(s *ExpenseService, i int) (uint, *dbmodel.Expense, error) {
+		item := req.Items[i]
+		expense, err := updateExpense(s, authCtx, item.ID, item.ExpenseUpdateRequest)
+		return item.ID, expense, err
+	})
+}
+
+// BulkDeleteExpenses godoc
+// @Summary Delete several expenses
+// @Description Delete up to 500 expenses in one transaction, each checked like a single delete. In atomic mode (default) nothing is deleted when any item fails; in best_effort mode the others are deleted. Deleting a transfer leg deletes the whole transfer.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param request body dto.ExpenseBulkDeleteRequest true "Expense IDs to delete"
+// @Success 200 {object} dto.ExpenseBulkResult "Items processed"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 422 {object} dto.ExpenseBulkResult "An item failed; nothing was deleted"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/bulk [delete]
+func (h *ExpenseHandler) BulkDeleteExpenses(c *gin.Context) {
+	var req dto.ExpenseBulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	h.runBulk(c, req.Mode, len(req.IDs), http.StatusOK, func(s *ExpenseService, i int) (uint, *dbmodel.Expense, error) {
+		return req.IDs[i], nil, deleteExpense(s, authCtx, req.IDs[i])
+	})
+}
+
+// runBulk runs op for n items through ExpenseService.Bulk and writes the
+// per-item results. op returns the ID given in the item (0 for creates) and
+// the saved expense, if any. okStatus is reported for successful items and,
+// unless the request was rolled back, as the response status.
+func (h *ExpenseHandler) runBulk(c *gin.Context, mode string, n int, okStatus int, op func(s *ExpenseService, i int) (uint, *dbmodel.Expense, error)) {
+	mode = strings.ToLower(mode)
+	if mode == "" {
+		mode = dto.BulkModeAtomic
+	}
+	if mode != dto.BulkModeAtomic && mode != dto.BulkModeBestEffort {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode, expected atomic or best_effort"})
+		return
+	}
+
+	ids := make([]uint, n)
+	expenses := make([]*dbmodel.Expense, n)
+	errs, rolledBack, err := h.Service.Bulk(n, mode == dto.BulkModeAtomic, func(s *ExpenseService, i int) (err error) {
+		ids[i], expenses[i], err = op(s, i)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply bulk operations"})
+		return
+	}
+
+	result := dto.ExpenseBulkResult{
+		Mode:       mode,
+		Total:      n,
+		RolledBack: rolledBack,
+		Results:    make([]dto.ExpenseBulkItemResult, n),
+	}
+	for i := range result.Results {
+		item := dto.ExpenseBulkItemResult{Index: i, ID: ids[i], Status: okStatus}
+		var reqErr *requestError
+		switch {
+		case errors.As(errs[i], &reqErr):
+			item.Status, item.Error = reqErr.status, reqErr.message
+			result.Failed++
+		case errs[i] != nil:
+			slog.Error("bulk expense operation failed", "index", i, "error", errs[i])
+			item.Status, item.Error = http.StatusInternalServerError, "Internal server error"
+			result.Failed++
+		case rolledBack:
+			item.Status, item.Error = http.StatusFailedDependency, "Not saved because another item failed"
+		default:
+			if e := expenses[i]; e != nil {
+				resp := toExpenseResponse(e)
+				item.ID, item.Expense = e.ID, &resp
+			}
+			result.Succeeded++
+		}
+		result.Results[i] = item
+	}
+	if rolledBack {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(okStatus, result)
+}
+
+// ListTrash godoc
+// @Summary List deleted expenses
+// @Description Get the expenses in the trash, most recently deleted first. They can be restored or purged until the retention job removes them.
+// @Tags expenses
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 25)"
+// @Success 200 {object} dto.ExpenseListResponse "Deleted expenses"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/trash [get]
+func (h *ExpenseHandler) ListTrash(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.TrashFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own expenses"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 25
+	}
+
+	expenses, total, err := h.Service.ListTrash(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted expenses"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ExpenseListResponse{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Count:    len(expenses),
+		Total:    total,
+		Data:     toExpenseResponseList(expenses),
+	})
+}
+
+// trashedExpense loads the deleted expense from the :id path parameter and
+// checks that the caller owns it. It writes the error response and returns nil
+// when the expense is not in the trash or belongs to someone else.
+func (h *ExpenseHandler) trashedExpense(c *gin.Context) *dbmodel.Expense {
+	authCtx := auth.GetAuthContext(c)
+	expense, err := h.Service.GetDeletedExpense(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found in trash"})
+		return nil
+	}
+	if authCtx.Role == auth.RoleUser && expense.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage your own deleted expenses"})
+		return nil
+	}
+	return expense
+}
+
+// RestoreExpense godoc
+// @Summary Restore a deleted expense
+// @Description Take an expense out of the trash. Restoring a transfer leg restores the whole transfer.
+// @Tags expenses
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Success 200 {object} dto.ExpenseResponse "Expense restored successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense not found in trash"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/trash/{id}/restore [post]
+func (h *ExpenseHandler) RestoreExpense(c *gin.Context) {
+	expense := h.trashedExpense(c)
+	if expense == nil {
+		return
+	}
+	if err := h.Service.RestoreExpense(expense, auth.GetAuthContext(c).UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore expense"})
+		return
+	}
+	c.JSON(http.StatusOK, toExpenseResponse(expense))
+}
+
+// PurgeExpense godoc
+// @Summary Permanently delete an expense
+// @Description Permanently delete an expense from the trash, with its attachments. Purging a transfer leg purges the whole transfer. This cannot be undone.
+// @Tags expenses
+// @Produce json
+// @Param id path int true "Expense ID"
+// @Success 200 {object} map[string]interface{} "Expense permanently deleted"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Expense not found in trash"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/trash/{id} [delete]
+func (h *ExpenseHandler) PurgeExpense(c *gin.Context) {
+	expense := h.trashedExpense(c)
+	if expense == nil {
+		return
+	}
+	if err := h.Service.PurgeExpense(c.Request.Context(), expense.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge expense"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Expense permanently deleted"})
+}
+
+// EmptyTrash godoc
+// @Summary Empty the trash
+// @Description Permanently delete every expense in the trash, with their attachments. This cannot be undone.
+// @Tags expenses
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Success 200 {object} map[string]interface{} "Number of purged expenses"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/trash [delete]
+func (h *ExpenseHandler) EmptyTrash(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	targetID := utils.ParseUint(c.Query("user_id"))
+	if authCtx.Role == auth.RoleUser && targetID != 0 && targetID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage your own deleted expenses"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser || targetID == 0 {
+		targetID = userID
+	}
+	purged, err := h.Service.PurgeTrash(c.Request.Context(), targetID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to empty trash"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Trash emptied", "purged": purged})
+}
+
+// ImportExpenses godoc
+// @Summary Import expenses from CSV, OFX/QFX, QIF or a bank statement
+// @Description Upload a statement file. For CSV (with a header line), mapping is a JSON object assigning fields (date, amount, kind, type, currency, description, account, external_id) to column headers or 1-based column numbers; date and amount are required. Rows without a kind take it from the sign of the amount; the account column holds account names. Bank statements (CSV or XLSX from VCB, VPBank, BIDV or Cake, see /expenses/import/banks) are recognized from their header and assigned to the account named after the bank (VCB, VPBANK, BIDV, CAKE) unless account_id is given. OFX rows keep their FITID, statements their reference number, and other rows a content hash as external ID; rows already imported into the same account are skipped as duplicates. Rows are validated like a single create. With dry_run the parsed rows and their errors are returned without saving; otherwise all rows are saved in one transaction, or none if any row is invalid.
+// @Tags expenses
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Statement file"
+// @Param format formData string false "csv, ofx, qfx, qif or statement (default: from the file extension)"
+// @Param bank formData string false "Statement bank: vcb, vpbank, bidv or cake (default: detected)"
+// @Param mapping formData string false "CSV column mapping as a JSON object: field -> column header or number"
+// @Param date_format formData string false "Date format, e.g. DD/MM/YYYY (default: YYYY-MM-DD, MM/DD/YYYY for QIF)"
+// @Param decimal_separator formData string false "Decimal separator: . (default) or ,"
+// @Param delimiter formData string false "Column delimiter (default: detected)"
+// @Param currency formData string false "Currency for rows without one (default: VND)"
+// @Param account_id formData int false "Account for rows without one"
+// @Param user_id formData int false "User ID"
+// @Param dry_run formData bool false "Validate only, do not save"
+// @Success 200 {object} dto.ExpenseImportResult "Dry-run result"
+// @Success 201 {object} dto.ExpenseImportResult "Rows imported"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 422 {object} dto.ExpenseImportResult "Some rows are invalid, nothing imported"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/import [post]
+func (h *ExpenseHandler) ImportExpenses(c *gin.Context) {
+	var req dto.ExpenseImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only import your own expenses"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.Currency == "" {
+		req.Currency = "VND"
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = importer.FormatFromFilename(fileHeader.Filename)
+	}
+	opts := importer.Options{
+		DateFormat:       req.DateFormat,
+		DecimalSeparator: req.DecimalSeparator,
+		Bank:             req.Bank,
+	}
+	if format == importer.FormatCSV {
+		if err := json.Unmarshal([]byte(req.Mapping), &opts.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping, expected a JSON object"})
+			return
+		}
+	}
+	if req.Delimiter != "" {
+		delimiter := []rune(req.Delimiter)
+		if req.Delimiter == "\\t" {
+			delimiter = []rune{'\t'}
+		}
+		if len(delimiter) != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Delimiter must be a single character"})
+			return
+		}
+		opts.Delimiter = delimiter[0]
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+	rows, err := importer.Parse(format, file, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format == importer.FormatStatement && req.AccountID != nil {
+		// Statement rows are tagged with the bank's account name; an explicit account wins.
+		for i := range rows {
+			rows[i].Account = ""
+		}
+	}
+
+	result, err := h.Service.ImportExpenses(req.UserID, rows, req.Currency, req.AccountID, req.DryRun, authCtx.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import expenses"})
+		return
+	}
+	switch {
+	case req.DryRun:
+		c.JSON(http.StatusOK, result)
+	case result.Invalid > 0:
+		c.JSON(http.StatusUnprocessableEntity, result)
+	default:
+		c.JSON(http.StatusCreated, result)
+	}
+}
+
+// ImportBanks godoc
+// @Summary List supported bank statements
+// @Description Get the bank codes accepted by the bank parameter of the import endpoint
+// @Tags expenses
+// @Produce json
+// @Success 200 {array} string "Bank codes"
+// @Security BearerAuth
+// @Router /expenses/import/banks [get]
+func (h *ExpenseHandler) ImportBanks(c *gin.Context) {
+	c.JSON(http.StatusOK, importer.StatementBanks())
+}
+
+// ParseNotification godoc
+// @Summary Parse a bank notification
+// @Description Extract amount, currency, direction, date/time and memo from a balance-change SMS or push notification (VCB, BIDV, VPBank, Cake, or generic "-150,000VND ... ND: memo" messages). Returns a draft expense, or creates it when create is true. The account defaults to the one named after the bank.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param notification body dto.NotificationParseRequest true "Notification text"
+// @Success 200 {object} dto.NotificationParseResponse "Draft expense"
+// @Success 201 {object} dto.NotificationParseResponse "Expense created"
+// @Failure 400 {object} map[string]interface{} "Invalid request or unrecognized notification"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Security BearerAuth
+// @Router /expenses/parse-notification [post]
+func (h *ExpenseHandler) ParseNotification(c *gin.Context) {
+	var req dto.NotificationParseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own expenses"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	n, err := importer.ParseNotification(req.Text, req.Bank, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kind := dbmodel.ExpenseKindIncome
+	if n.Amount.IsNegative() {
+		kind = dbmodel.ExpenseKindExpense
+	}
+	currency := n.Currency
+	if currency == "" {
+		currency = "VND"
+	}
+	accountID := req.AccountID
+	if accountID == nil && n.Bank != "" {
+		accountID = h.Service.AccountIDByName(req.UserID, n.Bank)
+	}
+	draft := dto.ExpenseCreateRequest{
+		UserID:      req.UserID,
+		Amount:      n.Amount,
+		Currency:    currency,
+		Kind:        string(kind),
+		Type:        strings.ToLower(strings.TrimSpace(req.Type)),
+		AccountID:   accountID,
+		Description: n.Memo,
+		Date:        n.Date,
+	}
+	resp := dto.NotificationParseResponse{Bank: n.Bank, Time: n.Time, Draft: draft}
+	if !req.Create {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	expense := dbmodel.Expense{
+		UserID:      draft.UserID,
+		Amount:      draft.Amount,
+		Currency:    draft.Currency,
+		Kind:        kind,
+		Type:        draft.Type,
+		AccountID:   draft.AccountID,
+		Description: draft.Description,
+		Date:        draft.Date,
+	}
+	if err := h.Service.AddExpense(&expense, authCtx.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created := toExpenseResponse(&expense)
+	resp.Expense = &created
+	c.JSON(http.StatusCreated, resp)
+}
+
+// CreateTransfer godoc
+// @Summary Transfer money between accounts
+// @Description Move money from one account to another. Writes a debit and a credit leg with kind "transfer" atomically; transfers are excluded from income and expense totals. Set to_amount for cross-currency transfers, otherwise the amount is converted at the current rate.
+// @Tags expenses
+// @Accept json
+// @Produce json
+// @Param transfer body dto.TransferCreateRequest true "Transfer details"
+// @Success 201 {object} dto.TransferResponse "Transfer created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Security BearerAuth
+// @Router /expenses/transfers [post]
+func (h *ExpenseHandler) CreateTransfer(c *gin.Context) {
+	var req dto.TransferCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own transfers"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.Date == "" {
+		req.Date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+		return
+	}
+	transfer, err := h.Service.CreateTransfer(req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toTransferResponse(transfer))
+}
+
+// GetTransfer godoc
+// @Summary Get a transfer
+// @Description Get a transfer with its debit and credit legs
+// @Tags expenses
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Success 200 {object} dto.TransferResponse "Transfer"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Transfer not found"
+// @Security BearerAuth
+// @Router /expenses/transfers/{id} [get]
+func (h *ExpenseHandler) GetTransfer(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	transfer, err := h.Service.GetTransferByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && transfer.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own transfers"})
+		return
+	}
+	c.JSON(http.StatusOK, toTransferResponse(transfer))
+}
+
+// DeleteTransfer godoc
+// @Summary Delete a transfer
+// @Description Delete a transfer together with both of its legs
+// @Tags expenses
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Success 200 {object} map[string]interface{} "Transfer deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Transfer not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/transfers/{id} [delete]
+func (h *ExpenseHandler) DeleteTransfer(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	transfer, err := h.Service.GetTransferByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && transfer.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own transfers"})
+		return
+	}
+	if err := h.Service.DeleteTransfer(transfer.ID, authCtx.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transfer"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer deleted successfully"})
+}
+
+// ListTags godoc
+// @Summary List tags
+// @Description Get the tag names of the current user
+// @Tags expenses
+// @Produce json
+// @Success 200 {array} string "List of tags"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /expenses/tags [get]
+func (h *ExpenseHandler) ListTags(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	tags, err := h.Service.ListTags(authCtx.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
 }
 
 // GetUniqueTypes godoc
@@ -1,22 +1,102 @@
 package expense
 
 import (
+	"encoding/json"
+
 	dbmodel "mindoh-service/internal/db"
 	"mindoh-service/internal/dto"
 )
 
 func toExpenseResponse(e *dbmodel.Expense) dto.ExpenseResponse {
-	return dto.ExpenseResponse{
-		ID:          e.ID,
-		UserID:      e.UserID,
-		Amount:      e.Amount,
-		Currency:    e.Currency,
-		Kind:        string(e.Kind),
-		Type:        e.Type,
-		Resource:    string(e.Resource),
-		Description: e.Description,
-		Date:        e.Date,
+	resp := dto.ExpenseResponse{
+		ID:              e.ID,
+		UserID:          e.UserID,
+		Amount:          e.Amount,
+		Currency:        e.Currency,
+		Kind:            string(e.Kind),
+		Type:            e.Type,
+		CategoryID:      e.CategoryID,
+		AccountID:       e.AccountID,
+		Tags:            toTagNames(e.Tags),
+		Splits:          toSplitResponses(e.Splits),
+		Description:     e.Description,
+		Date:            e.Date,
+		RecurringRuleID: e.RecurringRuleID,
+		TransferID:      e.TransferID,
+		ExternalID:      e.ExternalID,
+	}
+	if e.DeletedAt.Valid {
+		resp.DeletedAt = &e.DeletedAt.Time
+	}
+	return resp
+}
+
+func toTagNames(tags []dbmodel.Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func toTagModels(names []string) []dbmodel.Tag {
+	tags := make([]dbmodel.Tag, len(names))
+	for i, name := range names {
+		tags[i] = dbmodel.Tag{Name: name}
 	}
+	return tags
+}
+
+func toSplitResponses(splits []dbmodel.ExpenseSplit) []dto.ExpenseSplitResponse {
+	if len(splits) == 0 {
+		return nil
+	}
+	result := make([]dto.ExpenseSplitResponse, len(splits))
+	for i, s := range splits {
+		result[i] = dto.ExpenseSplitResponse{
+			ID:          s.ID,
+			Amount:      s.Amount,
+			Type:        s.Type,
+			CategoryID:  s.CategoryID,
+			Description: s.Description,
+		}
+	}
+	return result
+}
+
+func toSplitModels(lines []dto.ExpenseSplitRequest) []dbmodel.ExpenseSplit {
+	splits := make([]dbmodel.ExpenseSplit, len(lines))
+	for i, line := range lines {
+		splits[i] = dbmodel.ExpenseSplit{
+			Amount:      line.Amount,
+			Type:        line.Type,
+			CategoryID:  line.CategoryID,
+			Description: line.Description,
+		}
+	}
+	return splits
+}
+
+func toRevisionResponse(r *dbmodel.ExpenseRevision) dto.ExpenseRevisionResponse {
+	resp := dto.ExpenseRevisionResponse{
+		ID:         r.ID,
+		ExpenseID:  r.ExpenseID,
+		ActorID:    r.ActorID,
+		Action:     string(r.Action),
+		RevertedTo: r.RevertedTo,
+		CreatedAt:  r.CreatedAt,
+	}
+	json.Unmarshal([]byte(r.Changes), &resp.Changes)
+	json.Unmarshal([]byte(r.State), &resp.State)
+	return resp
+}
+
+func toRevisionResponseList(revisions []dbmodel.ExpenseRevision) []dto.ExpenseRevisionResponse {
+	result := make([]dto.ExpenseRevisionResponse, len(revisions))
+	for i := range revisions {
+		result[i] = toRevisionResponse(&revisions[i])
+	}
+	return result
 }
 
 func toExpenseResponseList(expenses []dbmodel.Expense) []dto.ExpenseResponse {
@@ -26,3 +106,24 @@ func toExpenseResponseList(expenses []dbmodel.Expense) []dto.ExpenseResponse {
 	}
 	return result
 }
+
+// toTransferResponse maps a transfer with its preloaded legs; the debit leg is
+// the negative one.
+func toTransferResponse(t *dbmodel.Transfer) dto.TransferResponse {
+	resp := dto.TransferResponse{
+		ID:            t.ID,
+		UserID:        t.UserID,
+		FromAccountID: t.FromAccountID,
+		ToAccountID:   t.ToAccountID,
+		Description:   t.Description,
+		Date:          t.Date,
+	}
+	for i := range t.Legs {
+		if t.Legs[i].Amount.IsNegative() {
+			resp.Debit = toExpenseResponse(&t.Legs[i])
+		} else {
+			resp.Credit = toExpenseResponse(&t.Legs[i])
+		}
+	}
+	return resp
+}
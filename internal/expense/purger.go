@@ -0,0 +1,42 @@
+package expense
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TrashPurger periodically and permanently deletes expenses that have been in
+// the trash for longer than Retention.
+type TrashPurger struct {
+	Service   *ExpenseService
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func NewTrashPurger(service *ExpenseService, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{Service: service, Retention: retention, Interval: interval}
+}
+
+// Start runs the purger once immediately and then on every tick, in the background.
+func (p *TrashPurger) Start() {
+	go func() {
+		p.run()
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			p.run()
+		}
+	}()
+}
+
+func (p *TrashPurger) run() {
+	before := time.Now().Add(-p.Retention)
+	purged, err := p.Service.PurgeTrash(context.Background(), 0, before)
+	if err != nil {
+		slog.Error("trash: purge run failed", "error", err)
+	}
+	if purged > 0 {
+		slog.Info("trash: purged deleted expenses", "count", purged, "before", before.Format(time.RFC3339))
+	}
+}
@@ -1,15 +1,26 @@
 package expense
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode"
 
 	dbmodel "mindoh-service/internal/db"
 	"mindoh-service/internal/dto"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrInvalidCursor is returned when a list cursor is malformed or was issued
+// for a different ordering.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // ExpenseRepository handles DB operations for expenses
 type ExpenseRepository struct {
 	DB *gorm.DB
@@ -21,7 +32,7 @@ func NewExpenseRepository(db *gorm.DB) *ExpenseRepository {
 
 func (r *ExpenseRepository) GetByID(id uint) (*dbmodel.Expense, error) {
 	var expense dbmodel.Expense
-	err := r.DB.First(&expense, id).Error
+	err := r.DB.Preload("Tags").Preload("Splits").First(&expense, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +43,13 @@ func (r *ExpenseRepository) Create(expense *dbmodel.Expense) error {
 	return r.DB.Create(expense).Error
 }
 
+// CreateBatch inserts all expenses in a single transaction.
+func (r *ExpenseRepository) CreateBatch(expenses []dbmodel.Expense) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(expenses, 500).Error
+	})
+}
+
 func (r *ExpenseRepository) Update(expense *dbmodel.Expense) error {
 	return r.DB.Save(expense).Error
 }
@@ -40,13 +58,262 @@ func (r *ExpenseRepository) UpdateFields(id uint, fields map[string]interface{})
 	return r.DB.Model(&dbmodel.Expense{}).Where("id = ?", id).Updates(fields).Error
 }
 
+// UpdateFieldsAndAssociations updates the given columns and, when they are not
+// nil, replaces the expense's tags and split lines, in one transaction.
+func (r *ExpenseRepository) UpdateFieldsAndAssociations(expense *dbmodel.Expense, fields map[string]interface{}, tags []dbmodel.Tag, splits []dbmodel.ExpenseSplit) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if len(fields) > 0 {
+			if err := tx.Model(&dbmodel.Expense{}).Where("id = ?", expense.ID).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
+		if tags != nil {
+			if err := tx.Model(expense).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
+		if splits == nil {
+			return nil
+		}
+		if err := tx.Where("expense_id = ?", expense.ID).Delete(&dbmodel.ExpenseSplit{}).Error; err != nil {
+			return err
+		}
+		if len(splits) == 0 {
+			return nil
+		}
+		for i := range splits {
+			splits[i].ID = 0
+			splits[i].ExpenseID = expense.ID
+		}
+		return tx.Create(&splits).Error
+	})
+}
+
+// ResolveTags returns the user's tags with the given (normalized) names,
+// creating the missing ones.
+func (r *ExpenseRepository) ResolveTags(userID uint, names []string) ([]dbmodel.Tag, error) {
+	tags := make([]dbmodel.Tag, 0, len(names))
+	if len(names) == 0 {
+		return tags, nil
+	}
+	rows := make([]dbmodel.Tag, len(names))
+	for i, name := range names {
+		rows[i] = dbmodel.Tag{UserID: userID, Name: name}
+	}
+	if err := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	err := r.DB.Where("user_id = ? AND name IN ?", userID, names).Order("name asc").Find(&tags).Error
+	return tags, err
+}
+
+// ListTagNames returns the names of the user's tags in alphabetical order.
+func (r *ExpenseRepository) ListTagNames(userID uint) ([]string, error) {
+	var names []string
+	err := r.DB.Model(&dbmodel.Tag{}).Where("user_id = ?", userID).Order("name asc").Pluck("name", &names).Error
+	return names, err
+}
+
 func (r *ExpenseRepository) Delete(id uint) error {
 	return r.DB.Delete(&dbmodel.Expense{}, id).Error
 }
 
+// GetWithDeletedByID returns the expense with the given ID, even when it is in the trash.
+func (r *ExpenseRepository) GetWithDeletedByID(id uint) (*dbmodel.Expense, error) {
+	var expense dbmodel.Expense
+	err := r.DB.Unscoped().First(&expense, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (r *ExpenseRepository) CreateRevisions(revisions []dbmodel.ExpenseRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	return r.DB.CreateInBatches(revisions, 500).Error
+}
+
+// ListRevisions returns the revisions of an expense, oldest first.
+func (r *ExpenseRepository) ListRevisions(expenseID uint) ([]dbmodel.ExpenseRevision, error) {
+	var revisions []dbmodel.ExpenseRevision
+	err := r.DB.Where("expense_id = ?", expenseID).Order("id asc").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *ExpenseRepository) GetRevision(id uint) (*dbmodel.ExpenseRevision, error) {
+	var revision dbmodel.ExpenseRevision
+	err := r.DB.First(&revision, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// ListDeleted returns one page of soft-deleted expenses, most recently deleted
+// first, and the number of deleted expenses. userID 0 lists every user's.
+func (r *ExpenseRepository) ListDeleted(userID uint, page, pageSize int) (expenses []dbmodel.Expense, total int64, err error) {
+	if err = r.deletedQuery(userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	query := r.deletedQuery(userID).Preload("Tags").Preload("Splits").Order("deleted_at desc, id desc")
+	if pageSize > 0 {
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	err = query.Find(&expenses).Error
+	return expenses, total, err
+}
+
+// GetDeletedByID returns the soft-deleted expense with the given ID.
+func (r *ExpenseRepository) GetDeletedByID(id uint) (*dbmodel.Expense, error) {
+	var expense dbmodel.Expense
+	err := r.DB.Unscoped().Preload("Tags").Preload("Splits").Where("deleted_at IS NOT NULL").First(&expense, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// DeletedIDs returns up to limit IDs of the user's soft-deleted expenses
+// (every user's for userID 0) that were deleted before the given time.
+func (r *ExpenseRepository) DeletedIDs(userID uint, before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.deletedQuery(userID).Where("deleted_at < ?", before).Order("id").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// deletedQuery selects the user's soft-deleted expenses, or every user's for userID 0.
+func (r *ExpenseRepository) deletedQuery(userID uint) *gorm.DB {
+	query := r.DB.Unscoped().Model(&dbmodel.Expense{}).Where("deleted_at IS NOT NULL")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	return query
+}
+
+// Restore undeletes the expense. Both legs of a transfer are restored together
+// with the transfer itself.
+func (r *ExpenseRepository) Restore(expense *dbmodel.Expense) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if expense.TransferID == nil {
+			return tx.Unscoped().Model(&dbmodel.Expense{}).Where("id = ?", expense.ID).Update("deleted_at", nil).Error
+		}
+		if err := tx.Unscoped().Model(&dbmodel.Expense{}).Where("transfer_id = ?", *expense.TransferID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&dbmodel.Transfer{}).Where("id = ?", *expense.TransferID).Update("deleted_at", nil).Error
+	})
+}
+
+// PurgeDeleted permanently deletes the given soft-deleted expenses, together
+// with the other leg and the record of deleted transfers. Their tags links,
+// split lines and attachment records go with them; the storage keys of the
+// attachments are returned so the caller can remove the files.
+func (r *ExpenseRepository) PurgeDeleted(ids []uint) (storageKeys []string, purged int64, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		var transferIDs []uint
+		if err := tx.Unscoped().Model(&dbmodel.Expense{}).
+			Where("id IN ? AND deleted_at IS NOT NULL AND transfer_id IS NOT NULL", ids).
+			Distinct().Pluck("transfer_id", &transferIDs).Error; err != nil {
+			return err
+		}
+		var expenseIDs []uint
+		if err := tx.Unscoped().Model(&dbmodel.Expense{}).
+			Where("deleted_at IS NOT NULL AND (id IN ? OR transfer_id IN ?)", ids, transferIDs).
+			Pluck("id", &expenseIDs).Error; err != nil {
+			return err
+		}
+		if len(expenseIDs) == 0 {
+			return nil
+		}
+		if err := tx.Model(&dbmodel.Attachment{}).Where("expense_id IN ?", expenseIDs).Pluck("storage_key", &storageKeys).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", expenseIDs).Delete(&dbmodel.Expense{})
+		if res.Error != nil {
+			return res.Error
+		}
+		purged = res.RowsAffected
+		if len(transferIDs) == 0 {
+			return nil
+		}
+		return tx.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", transferIDs).Delete(&dbmodel.Transfer{}).Error
+	})
+	return storageKeys, purged, err
+}
+
+// CreateTransfer inserts the transfer and its legs in a single transaction.
+func (r *ExpenseRepository) CreateTransfer(transfer *dbmodel.Transfer, legs []*dbmodel.Expense) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Legs").Create(transfer).Error; err != nil {
+			return err
+		}
+		for _, leg := range legs {
+			leg.TransferID = &transfer.ID
+			if err := tx.Create(leg).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ExpenseRepository) GetTransferByID(id uint) (*dbmodel.Transfer, error) {
+	var transfer dbmodel.Transfer
+	err := r.DB.Preload("Legs").First(&transfer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// DeleteTransfer deletes the transfer together with both of its legs.
+func (r *ExpenseRepository) DeleteTransfer(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transfer_id = ?", id).Delete(&dbmodel.Expense{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&dbmodel.Transfer{}, id).Error
+	})
+}
+
+// ExistsRecurringOccurrence reports whether the rule already produced an expense on date.
+// Soft-deleted rows count too, so a deleted occurrence is not generated again.
+func (r *ExpenseRepository) ExistsRecurringOccurrence(ruleID uint, date string) (bool, error) {
+	var count int64
+	err := r.DB.Unscoped().Model(&dbmodel.Expense{}).
+		Where("recurring_rule_id = ? AND date = ?", ruleID, date).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ExistingExternalIDs returns which of ids the user already imported into the
+// account (nil for expenses without an account). Soft-deleted rows count too,
+// so a deleted import is not brought back by importing the statement again.
+func (r *ExpenseRepository) ExistingExternalIDs(userID uint, accountID *uint, ids []string) (map[string]bool, error) {
+	var found []string
+	query := r.DB.Unscoped().Model(&dbmodel.Expense{}).Where("user_id = ? AND external_id IN ?", userID, ids)
+	if accountID == nil {
+		query = query.Where("account_id IS NULL")
+	} else {
+		query = query.Where("account_id = ?", *accountID)
+	}
+	if err := query.Pluck("external_id", &found).Error; err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
 func (r *ExpenseRepository) GetUniqueTypes(userID uint) ([]string, error) {
 	var types []string
-	query := r.DB.Model(&dbmodel.Expense{}).Distinct("type").Where("type != ''").Order("type asc")
+	query := r.DB.Model(&dbmodel.Expense{}).Distinct("type").
+		Where("type != '' AND kind <> ?", dbmodel.ExpenseKindTransfer).
+		Order("type asc")
 	if userID != 0 {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -64,11 +331,29 @@ func (r *ExpenseRepository) buildBaseQuery(filter dto.ExpenseFilter) *gorm.DB {
 		q = q.Where("kind = ?", filter.Kind)
 	}
 	if len(filter.Types) > 0 {
-		q = q.Where("type IN ?", filter.Types)
+		// A split expense also matches through the types of its lines.
+		q = q.Where("(type IN ? OR EXISTS (SELECT 1 FROM expense_splits es WHERE es.expense_id = expenses.id AND es.type IN ?))", filter.Types, filter.Types)
 	}
 	if len(filter.Currencies) > 0 {
 		q = q.Where("currency IN ?", filter.Currencies)
 	}
+	if len(filter.AccountIDs) > 0 {
+		q = q.Where("account_id IN ?", filter.AccountIDs)
+	}
+	if len(filter.Tags) > 0 {
+		tagged := r.DB.Table("expense_tags").
+			Select("expense_tags.expense_id").
+			Joins("JOIN tags ON tags.id = expense_tags.tag_id").
+			Where("tags.name IN ?", normalizeTags(filter.Tags))
+		if strings.ToLower(filter.TagsMode) == "all" {
+			tagged = tagged.Group("expense_tags.expense_id").
+				Having("COUNT(DISTINCT tags.name) = ?", len(normalizeTags(filter.Tags)))
+		}
+		q = q.Where("id IN (?)", tagged)
+	}
+	if tsquery := searchQuery(filter.Q); tsquery != "" {
+		q = q.Where(dbmodel.ExpenseSearchVector+" @@ to_tsquery('simple', ?)", tsquery)
+	}
 	if filter.From != "" {
 		q = q.Where("date >= ?", filter.From)
 	}
@@ -78,14 +363,52 @@ func (r *ExpenseRepository) buildBaseQuery(filter dto.ExpenseFilter) *gorm.DB {
 	return q
 }
 
+// searchQuery turns free text into a tsquery in which every word must match
+// the start of a word of the document, so "gra dal" finds "Grab to Dalat".
+// It returns "" when the text has no words.
+func searchQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// Highlights returns the description of each of the given expenses with the
+// words matching the search text wrapped in <mark></mark>. Expenses whose
+// description has no match are left out.
+func (r *ExpenseRepository) Highlights(ids []uint, text string) (map[uint]string, error) {
+	tsquery := searchQuery(text)
+	result := make(map[uint]string)
+	if tsquery == "" || len(ids) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		ID        uint   `gorm:"column:id"`
+		Highlight string `gorm:"column:highlight"`
+	}
+	err := r.DB.Model(&dbmodel.Expense{}).
+		Select("id, ts_headline('simple', description, to_tsquery('simple', ?), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight", tsquery).
+		Where("id IN ? AND description <> ''", ids).
+		Scan(&rows).Error
+	for _, row := range rows {
+		if strings.Contains(row.Highlight, "<mark>") {
+			result[row.ID] = row.Highlight
+		}
+	}
+	return result, err
+}
+
 // AggregateMeta runs a single lightweight GROUP BY query to compute
 // total count, income/expense counts, and per-currency totals — no row fetching.
 func (r *ExpenseRepository) AggregateMeta(filter dto.ExpenseFilter) (total, incomeCount, expenseCount int, byCurrency map[string]*dto.CurrencySummary, err error) {
 	type row struct {
-		Kind     string  `gorm:"column:kind"`
-		Currency string  `gorm:"column:currency"`
-		Cnt      int     `gorm:"column:cnt"`
-		SumAmt   float64 `gorm:"column:sum_amt"`
+		Kind     string          `gorm:"column:kind"`
+		Currency string          `gorm:"column:currency"`
+		Cnt      int             `gorm:"column:cnt"`
+		SumAmt   decimal.Decimal `gorm:"column:sum_amt"`
 	}
 	var rows []row
 	err = r.buildBaseQuery(filter).
@@ -98,29 +421,36 @@ func (r *ExpenseRepository) AggregateMeta(filter dto.ExpenseFilter) (total, inco
 	byCurrency = map[string]*dto.CurrencySummary{}
 	for _, rw := range rows {
 		total += rw.Cnt
-		if rw.Kind == "income" {
-			incomeCount += rw.Cnt
-		} else {
-			expenseCount += rw.Cnt
-		}
 		cs, ok := byCurrency[rw.Currency]
 		if !ok {
 			cs = &dto.CurrencySummary{}
 			byCurrency[rw.Currency] = cs
 		}
-		if rw.Kind == "income" {
-			cs.TotalIncome += rw.SumAmt
-		} else {
-			cs.TotalExpense += rw.SumAmt
+		// Transfer legs move money between accounts: they count towards the
+		// per-currency balance but not towards the income/expense totals.
+		switch dbmodel.ExpenseKind(rw.Kind) {
+		case dbmodel.ExpenseKindIncome:
+			incomeCount += rw.Cnt
+			cs.TotalIncome = cs.TotalIncome.Add(rw.SumAmt)
+		case dbmodel.ExpenseKindTransfer:
+			cs.TotalTransfer = cs.TotalTransfer.Add(rw.SumAmt)
+		default:
+			expenseCount += rw.Cnt
+			cs.TotalExpense = cs.TotalExpense.Add(rw.SumAmt)
 		}
-		cs.TotalBalance += rw.SumAmt
+		cs.TotalBalance = cs.TotalBalance.Add(rw.SumAmt)
 	}
 	return
 }
 
-func (r *ExpenseRepository) ListByFilter(filter dto.ExpenseFilter) ([]dbmodel.Expense, error) {
-	var expenses []dbmodel.Expense
+// orderClause returns the ORDER BY clause requested by filter, defaulting to date desc.
+func orderClause(filter dto.ExpenseFilter) string {
+	col, dir := orderColumn(filter)
+	return col + " " + dir
+}
 
+// orderColumn returns the column and direction requested by filter.
+func orderColumn(filter dto.ExpenseFilter) (string, string) {
 	allowedColumns := map[string]string{
 		"date":       "date",
 		"amount":     "amount",
@@ -137,38 +467,158 @@ func (r *ExpenseRepository) ListByFilter(filter dto.ExpenseFilter) ([]dbmodel.Ex
 	if strings.ToLower(filter.OrderDir) == "asc" {
 		orderDir = "asc"
 	}
+	return orderCol, orderDir
+}
+
+// listCursor is the decoded form of the opaque cursor returned with a list
+// page: the ordering it was issued for and the order value and ID of the last
+// row of the page.
+type listCursor struct {
+	Order string          `json:"o"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+func encodeCursor(col, dir string, e *dbmodel.Expense) string {
+	var value interface{}
+	switch col {
+	case "amount":
+		value = e.Amount
+	case "type":
+		value = e.Type
+	case "kind":
+		value = string(e.Kind)
+	case "currency":
+		value = e.Currency
+	case "created_at":
+		value = e.CreatedAt
+	default:
+		value = e.Date
+	}
+	raw, _ := json.Marshal(value)
+	b, _ := json.Marshal(listCursor{Order: col + " " + dir, Value: raw, ID: e.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor returns the order value and ID stored in cursor, typed for the
+// order column so the keyset comparison is done on the column's type.
+func decodeCursor(cursor, col, dir string) (interface{}, uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var c listCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Order != col+" "+dir {
+		return nil, 0, ErrInvalidCursor
+	}
+	var value interface{}
+	switch col {
+	case "amount":
+		var d decimal.Decimal
+		err = json.Unmarshal(c.Value, &d)
+		value = d
+	case "created_at":
+		var t time.Time
+		err = json.Unmarshal(c.Value, &t)
+		value = t
+	default:
+		var s string
+		err = json.Unmarshal(c.Value, &s)
+		value = s
+	}
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	return value, c.ID, nil
+}
 
-	query := r.buildBaseQuery(filter).Order(orderCol + " " + orderDir)
+// ListByFilter returns one page of matching expenses, ordered by the requested
+// column with the ID as tie-breaker. Without a cursor the page is selected by
+// page/page_size. With a cursor it continues after the row the cursor points
+// at (keyset pagination), so rows inserted in between do not shift the pages.
+// nextCursor is set when more rows follow the returned page.
+func (r *ExpenseRepository) ListByFilter(filter dto.ExpenseFilter) (expenses []dbmodel.Expense, nextCursor string, err error) {
+	col, dir := orderColumn(filter)
+	query := r.buildBaseQuery(filter).Preload("Tags").Preload("Splits").Order(fmt.Sprintf("%s %s, id %s", col, dir, dir))
 
-	// DB-level pagination
-	if filter.PageSize > 0 {
+	// DB-level pagination; one extra row tells whether a next page exists.
+	limit := filter.PageSize
+	if filter.Cursor != "" {
+		value, id, err := decodeCursor(filter.Cursor, col, dir)
+		if err != nil {
+			return nil, "", err
+		}
+		cmp := "<"
+		if dir == "asc" {
+			cmp = ">"
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", col, cmp), value, id)
+	} else if limit > 0 {
 		page := filter.Page
 		if page < 1 {
 			page = 1
 		}
-		offset := (page - 1) * filter.PageSize
-		query = query.Limit(filter.PageSize).Offset(offset)
+		query = query.Offset((page - 1) * limit)
+	}
+	if limit > 0 {
+		query = query.Limit(limit + 1)
 	}
 
-	err := query.Find(&expenses).Error
-	return expenses, err
+	if err = query.Find(&expenses).Error; err != nil {
+		return nil, "", err
+	}
+	if limit > 0 && len(expenses) > limit {
+		expenses = expenses[:limit]
+		nextCursor = encodeCursor(col, dir, &expenses[limit-1])
+	}
+	return expenses, nextCursor, nil
+}
+
+// CountByFilter returns how many expenses match filter, ignoring pagination.
+func (r *ExpenseRepository) CountByFilter(filter dto.ExpenseFilter) (int64, error) {
+	var count int64
+	err := r.buildBaseQuery(filter).Count(&count).Error
+	return count, err
 }
 
 // ListAllByFilter fetches all matching rows with no LIMIT/OFFSET — used by the summary endpoint
 // which needs every matching record to compute aggregated totals.
 func (r *ExpenseRepository) ListAllByFilter(filter dto.ExpenseFilter) ([]dbmodel.Expense, error) {
 	var expenses []dbmodel.Expense
-	err := r.buildBaseQuery(filter).Order("date desc").Find(&expenses).Error
+	err := r.buildBaseQuery(filter).Preload("Splits").Order("date desc").Find(&expenses).Error
 	return expenses, err
 }
 
+// StreamByFilter calls fn for every matching row in the requested order, ignoring
+// pagination. Rows are read from a cursor, so memory use does not grow with the
+// result size. Iteration stops at the first error returned by fn.
+func (r *ExpenseRepository) StreamByFilter(filter dto.ExpenseFilter, fn func(*dbmodel.Expense) error) error {
+	rows, err := r.buildBaseQuery(filter).Order(orderClause(filter) + ", id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var expense dbmodel.Expense
+		if err := r.DB.ScanRows(rows, &expense); err != nil {
+			return err
+		}
+		if err := fn(&expense); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // GroupAggRow is one row returned by ListGroupsAggByFilter.
 type GroupAggRow struct {
-	Bucket   string  `gorm:"column:bucket"`
-	Currency string  `gorm:"column:currency"`
-	Type     string  `gorm:"column:type"`
-	Kind     string  `gorm:"column:kind"`
-	Total    float64 `gorm:"column:total"`
+	Bucket     string          `gorm:"column:bucket"`
+	Currency   string          `gorm:"column:currency"`
+	Type       string          `gorm:"column:type"`
+	CategoryID *uint           `gorm:"column:category_id"`
+	Kind       string          `gorm:"column:kind"`
+	Total      decimal.Decimal `gorm:"column:total"`
+	Date       string          `gorm:"column:date"` // only set in historical rate mode
 }
 
 // bucketSQL returns the PostgreSQL expression that maps a varchar date (YYYY-MM-DD)
@@ -191,8 +641,11 @@ func bucketSQL(groupBy string) (string, error) {
 
 // ListGroupsAggByFilter returns all time-bucket aggregation rows for the filter.
 // Sorting and pagination are handled in the service layer (Go-side) so that
-// computed fields like income/expense/balance (which require live exchange-rate
-// conversion) can be sorted accurately.
+// computed fields like income/expense/balance (which require exchange-rate
+// conversion) can be sorted accurately. Split expenses contribute their lines,
+// each with its own type and category, instead of the expense's single type.
+// Unless the rate mode is current, rows are also split by date so each can be
+// converted at the rate of its day.
 func (r *ExpenseRepository) ListGroupsAggByFilter(filter dto.GroupsFilter) (rows []GroupAggRow, err error) {
 	expr, err := bucketSQL(filter.GroupBy)
 	if err != nil {
@@ -204,13 +657,33 @@ func (r *ExpenseRepository) ListGroupsAggByFilter(filter dto.GroupsFilter) (rows
 		Kind:       filter.Kind,
 		Types:      filter.Types,
 		Currencies: filter.Currencies,
+		AccountIDs: filter.AccountIDs,
+		Tags:       filter.Tags,
+		TagsMode:   filter.TagsMode,
+		Q:          filter.Q,
 		From:       filter.From,
 		To:         filter.To,
 	}
 
-	err = r.buildBaseQuery(lf).
-		Select(fmt.Sprintf("%s AS bucket, currency, type, kind, SUM(amount) AS total", expr)).
-		Group(fmt.Sprintf("%s, currency, type, kind", expr)).
+	columns := fmt.Sprintf("%s AS bucket, e.currency, "+
+		"CASE WHEN s.id IS NULL THEN e.type ELSE s.type END AS type, "+
+		"CASE WHEN s.id IS NULL THEN e.category_id ELSE s.category_id END AS category_id, "+
+		"e.kind, SUM(COALESCE(s.amount, e.amount)) AS total", expr)
+	group := "1, 2, 3, 4, 5"
+	if filter.RateMode != dto.RateModeCurrent {
+		columns += ", e.date"
+		group += ", 7"
+	}
+
+	base := r.buildBaseQuery(lf).Select("id, date, currency, type, category_id, kind, amount")
+	q := r.DB.Table("(?) AS e", base).
+		Joins("LEFT JOIN expense_splits s ON s.expense_id = e.id")
+	if len(filter.Types) > 0 {
+		// Of a split expense only the lines of the filtered types count.
+		q = q.Where("s.id IS NULL OR s.type IN ?", filter.Types)
+	}
+	err = q.Select(columns).
+		Group(group).
 		Order("bucket DESC").
 		Scan(&rows).Error
 	return
@@ -15,9 +15,26 @@ func RegisterExpenseRoutes(r *gin.Engine, a auth.IAuthService, service *ExpenseS
 		group.POST("/", handler.AddExpense)
 		group.PUT("/:id", handler.UpdateExpense)
 		group.DELETE("/:id", handler.DeleteExpense)
+		group.GET("/:id/history", handler.ExpenseHistory)
+		group.POST("/:id/history/:revision_id/revert", handler.RevertExpense)
+		group.POST("/bulk", handler.BulkCreateExpenses)
+		group.PATCH("/bulk", handler.BulkUpdateExpenses)
+		group.DELETE("/bulk", handler.BulkDeleteExpenses)
+		group.GET("/trash", handler.ListTrash)
+		group.DELETE("/trash", handler.EmptyTrash)
+		group.POST("/trash/:id/restore", handler.RestoreExpense)
+		group.DELETE("/trash/:id", handler.PurgeExpense)
 		group.GET("/", handler.ListExpenses)
 		group.GET("/types", handler.GetUniqueTypes)
+		group.GET("/tags", handler.ListTags)
 		group.GET("/summary", handler.Summary)
 		group.GET("/groups", handler.Groups)
+		group.POST("/import", handler.ImportExpenses)
+		group.GET("/import/banks", handler.ImportBanks)
+		group.POST("/parse-notification", handler.ParseNotification)
+		group.GET("/export", handler.ExportExpenses)
+		group.POST("/transfers", handler.CreateTransfer)
+		group.GET("/transfers/:id", handler.GetTransfer)
+		group.DELETE("/transfers/:id", handler.DeleteTransfer)
 	}
 }
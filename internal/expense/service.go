@@ -1,57 +1,619 @@
 package expense
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
-	"mindoh-service/internal/currency"
-	dbmodel "mindoh-service/internal/db"
-	"mindoh-service/internal/dto"
+	"fmt"
+	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+
+	"mindoh-service/internal/account"
+	"mindoh-service/internal/category"
+	"mindoh-service/internal/currency"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+	"mindoh-service/internal/exporter"
+	"mindoh-service/internal/importer"
+	"mindoh-service/internal/money"
+	"mindoh-service/internal/storage"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
+// ErrUnknownCurrency is returned when no exchange rate is known for a currency.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ErrInvalidComparison is returned for a summary comparison that cannot be
+// computed, e.g. without a from..to window.
+var ErrInvalidComparison = errors.New("invalid comparison")
+
+// ErrRevisionNotFound is returned when reverting to a revision that is not one
+// of the expense's.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+var errTransferKind = errors.New("transfers must be created and deleted through the transfers endpoint")
+
+// trashPurgeBatch is how many deleted expenses are purged per transaction.
+const trashPurgeBatch = 500
+
 // ExpenseService handles business logic for expenses
 type ExpenseService struct {
-	Repo *ExpenseRepository
+	Repo         *ExpenseRepository
+	AccountRepo  *account.AccountRepository
+	CategoryRepo *category.CategoryRepository
+	Storage      storage.Storage       // attachment files, removed when an expense is purged
+	Rates        *currency.RateHistory // live rates and daily snapshots for historical conversion
 }
 
-func NewExpenseService(repo *ExpenseRepository) *ExpenseService {
-	return &ExpenseService{Repo: repo}
+func NewExpenseService(repo *ExpenseRepository, accountRepo *account.AccountRepository, categoryRepo *category.CategoryRepository, store storage.Storage, rates *currency.RateHistory) *ExpenseService {
+	return &ExpenseService{Repo: repo, AccountRepo: accountRepo, CategoryRepo: categoryRepo, Storage: store, Rates: rates}
 }
 
-func (s *ExpenseService) AddExpense(expense *dbmodel.Expense) error {
-	// Validate amount sign based on kind
-	if expense.Kind == dbmodel.ExpenseKindExpense && expense.Amount > 0 {
+// ValidateAmount checks that the amount sign matches the kind (expenses are
+// stored negative, incomes positive) and that the amount has no more decimals
+// than the minor unit of currency.
+func ValidateAmount(kind dbmodel.ExpenseKind, amount decimal.Decimal, currency string) error {
+	if kind == dbmodel.ExpenseKindExpense && amount.IsPositive() {
 		return errors.New("expense amount must be negative")
 	}
-	if expense.Kind == dbmodel.ExpenseKindIncome && expense.Amount < 0 {
+	if kind == dbmodel.ExpenseKindIncome && amount.IsNegative() {
 		return errors.New("income amount must be positive")
 	}
-	return s.Repo.Create(expense)
+	return money.Validate(amount, currency)
+}
+
+// ValidateAccount checks that accountID (when set) is an active account of userID.
+func (s *ExpenseService) ValidateAccount(userID uint, accountID *uint) error {
+	if accountID == nil {
+		return nil
+	}
+	_, err := s.activeAccount(userID, *accountID)
+	return err
+}
+
+func (s *ExpenseService) activeAccount(userID, accountID uint) (*dbmodel.Account, error) {
+	acc, err := s.AccountRepo.GetByID(accountID)
+	if err != nil || acc.UserID != userID {
+		return nil, errors.New("account not found")
+	}
+	if acc.Archived {
+		return nil, errors.New("account is archived")
+	}
+	return acc, nil
+}
+
+// validateNewExpense applies the rules shared by AddExpense and ImportExpenses.
+// validateAccount is passed in so an import can cache account lookups.
+func validateNewExpense(expense *dbmodel.Expense, validateAccount func(userID uint, accountID *uint) error) error {
+	if expense.Kind == dbmodel.ExpenseKindTransfer {
+		return errTransferKind
+	}
+	if err := money.ValidateCurrency(expense.Currency); err != nil {
+		return err
+	}
+	if err := ValidateAmount(expense.Kind, expense.Amount, expense.Currency); err != nil {
+		return err
+	}
+	return validateAccount(expense.UserID, expense.AccountID)
+}
+
+// assignCategory links an income or expense to its category. An explicit
+// CategoryID must be visible to the user and match the kind; the type then
+// becomes the category name. Otherwise the category is looked up from the
+// type, and a custom category is created for a type that has none yet.
+func (s *ExpenseService) assignCategory(expense *dbmodel.Expense) (err error) {
+	expense.CategoryID, expense.Type, err = s.resolveCategory(expense.UserID, expense.Kind, expense.CategoryID, expense.Type)
+	return
+}
+
+// resolveCategory implements assignCategory for an expense or a split line and
+// returns the category ID and type to store.
+func (s *ExpenseService) resolveCategory(userID uint, kind dbmodel.ExpenseKind, categoryID *uint, typ string) (*uint, string, error) {
+	if categoryID != nil {
+		cat, err := s.CategoryRepo.GetByID(*categoryID)
+		if err != nil || !category.Visible(cat, userID) {
+			return nil, "", errors.New("category not found")
+		}
+		if cat.Kind != kind {
+			return nil, "", errors.New("category kind must match the expense kind")
+		}
+		return categoryID, strings.ToLower(cat.Name), nil
+	}
+	if typ == "" {
+		return nil, typ, nil
+	}
+	cat, err := s.categoryForType(userID, kind, typ)
+	if err != nil {
+		return nil, "", err
+	}
+	return &cat.ID, typ, nil
+}
+
+// prepareSplits validates the split lines of an expense and assigns their
+// categories. A split needs at least two lines, each with a type or category
+// and the sign of the expense kind, and the lines must add up to the expense
+// amount. An expense without a type takes the type of its largest line.
+func (s *ExpenseService) prepareSplits(expense *dbmodel.Expense) error {
+	if len(expense.Splits) == 0 {
+		return nil
+	}
+	if len(expense.Splits) < 2 {
+		return errors.New("a split needs at least two lines")
+	}
+	sum := decimal.Zero
+	largest := 0
+	for i := range expense.Splits {
+		line := &expense.Splits[i]
+		line.Type = strings.ToLower(strings.TrimSpace(line.Type))
+		if line.Amount.IsZero() {
+			return fmt.Errorf("split line %d: amount must not be zero", i+1)
+		}
+		if err := ValidateAmount(expense.Kind, line.Amount, expense.Currency); err != nil {
+			return fmt.Errorf("split line %d: %w", i+1, err)
+		}
+		if line.Type == "" && line.CategoryID == nil {
+			return fmt.Errorf("split line %d: type or category_id is required", i+1)
+		}
+		if len(line.Type) > 32 {
+			return fmt.Errorf("split line %d: type must be at most 32 characters", i+1)
+		}
+		categoryID, typ, err := s.resolveCategory(expense.UserID, expense.Kind, line.CategoryID, line.Type)
+		if err != nil {
+			return fmt.Errorf("split line %d: %w", i+1, err)
+		}
+		line.CategoryID, line.Type = categoryID, typ
+		sum = sum.Add(line.Amount)
+		if line.Amount.Abs().GreaterThan(expense.Splits[largest].Amount.Abs()) {
+			largest = i
+		}
+	}
+	if !sum.Equal(expense.Amount) {
+		return fmt.Errorf("split lines add up to %v, expected %v", sum, expense.Amount)
+	}
+	if expense.Type == "" && expense.CategoryID == nil {
+		expense.Type = expense.Splits[largest].Type
+	}
+	return nil
+}
+
+// categoryForType returns the user's or default category named after typ (or
+// its alias in dbmodel.TypeAliases), creating a top-level custom category when
+// there is none.
+func (s *ExpenseService) categoryForType(userID uint, kind dbmodel.ExpenseKind, typ string) (*dbmodel.Category, error) {
+	cat, err := s.CategoryRepo.FindByName(userID, kind, typ, dbmodel.CategoryNameForType(typ))
+	if err != nil || cat != nil {
+		return cat, err
+	}
+	cat = &dbmodel.Category{UserID: &userID, Kind: kind, Name: typ}
+	err = s.CategoryRepo.Create(cat)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// Created concurrently by another request.
+		return s.CategoryRepo.FindByName(userID, kind, typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+// AccountIDByName returns the ID of the user's account with the given name,
+// or nil when there is none.
+func (s *ExpenseService) AccountIDByName(userID uint, name string) *uint {
+	acc, err := s.AccountRepo.GetByUserAndName(userID, name)
+	if err != nil {
+		return nil
+	}
+	return &acc.ID
+}
+
+// AddExpense validates and creates an expense on behalf of actorID (0 for the
+// service itself). Tags only need a Name; missing tags are created for the
+// user. The category is derived from the type when not given.
+func (s *ExpenseService) AddExpense(expense *dbmodel.Expense, actorID uint) error {
+	if err := validateNewExpense(expense, s.ValidateAccount); err != nil {
+		return err
+	}
+	if err := s.prepareSplits(expense); err != nil {
+		return err
+	}
+	if err := s.assignCategory(expense); err != nil {
+		return err
+	}
+	if len(expense.Tags) > 0 {
+		names := make([]string, len(expense.Tags))
+		for i, t := range expense.Tags {
+			names[i] = t.Name
+		}
+		tags, err := s.resolveTags(expense.UserID, names)
+		if err != nil {
+			return err
+		}
+		expense.Tags = tags
+	}
+	return s.inTx(func(tx *ExpenseService) error {
+		if err := tx.Repo.Create(expense); err != nil {
+			return err
+		}
+		return tx.recordRevisions(newRevision(expense.ID, actorID, dbmodel.RevisionCreate, nil, stateOf(expense)))
+	})
+}
+
+// normalizeTags lower-cases and trims tag names, dropping blanks and duplicates.
+func normalizeTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+func (s *ExpenseService) resolveTags(userID uint, names []string) ([]dbmodel.Tag, error) {
+	names = normalizeTags(names)
+	for _, name := range names {
+		if len(name) > 32 {
+			return nil, fmt.Errorf("tag %q must be at most 32 characters", name)
+		}
+	}
+	return s.Repo.ResolveTags(userID, names)
+}
+
+func (s *ExpenseService) ListTags(userID uint) ([]string, error) {
+	return s.Repo.ListTagNames(userID)
+}
+
+// ImportExpenses turns parsed rows into expenses for userID and validates each
+// one with the same rules as AddExpense. Rows without a currency or account
+// value fall back to defaultCurrency and defaultAccountID; rows without a kind
+// take it from the sign of the amount. Rows whose external ID was already
+// imported for the same account are reported as duplicates and skipped.
+// Nothing is written in dry-run mode or when any row is invalid; otherwise all
+// new rows are inserted in one transaction, recorded as created by actorID.
+func (s *ExpenseService) ImportExpenses(userID uint, rows []importer.Row, defaultCurrency string, defaultAccountID *uint, dryRun bool, actorID uint) (*dto.ExpenseImportResult, error) {
+	accounts, err := s.AccountRepo.ListByUser(userID, true)
+	if err != nil {
+		return nil, err
+	}
+	accountsByName := make(map[string]uint, len(accounts))
+	for _, acc := range accounts {
+		accountsByName[strings.ToLower(acc.Name)] = acc.ID
+	}
+	accountErrs := map[uint]error{}
+	validateAccount := func(userID uint, accountID *uint) error {
+		if accountID == nil {
+			return nil
+		}
+		if err, ok := accountErrs[*accountID]; ok {
+			return err
+		}
+		err := s.ValidateAccount(userID, accountID)
+		accountErrs[*accountID] = err
+		return err
+	}
+
+	result := &dto.ExpenseImportResult{DryRun: dryRun, Total: len(rows), Rows: make([]dto.ExpenseImportRow, len(rows))}
+	expenses := make([]dbmodel.Expense, len(rows))
+	for i, row := range rows {
+		expense := &expenses[i]
+		*expense = dbmodel.Expense{
+			UserID:      userID,
+			Amount:      row.Amount,
+			Currency:    strings.ToUpper(row.Currency),
+			Kind:        dbmodel.ExpenseKind(strings.ToLower(row.Kind)),
+			Type:        strings.ToLower(row.Type),
+			AccountID:   defaultAccountID,
+			Description: row.Description,
+			Date:        row.Date,
+		}
+		if row.ExternalID != "" {
+			externalID := row.ExternalID
+			expense.ExternalID = &externalID
+		}
+		if expense.Currency == "" {
+			expense.Currency = defaultCurrency
+		}
+		if expense.Kind == "" {
+			expense.Kind = dbmodel.ExpenseKindIncome
+			if expense.Amount.IsNegative() {
+				expense.Kind = dbmodel.ExpenseKindExpense
+			}
+		}
+		rowErr := row.Error
+		if rowErr == "" {
+			rowErr = checkImportRow(expense, row.Account, accountsByName, validateAccount)
+		}
+		result.Rows[i] = dto.ExpenseImportRow{Line: row.Line, Expense: toExpenseResponse(expense), Error: rowErr}
+		if rowErr != "" {
+			result.Invalid++
+		} else {
+			result.Valid++
+		}
+	}
+
+	if err := s.markDuplicates(userID, expenses, result); err != nil {
+		return nil, err
+	}
+	if dryRun || result.Invalid > 0 {
+		return result, nil
+	}
+	var toCreate []dbmodel.Expense
+	var positions []int
+	for i := range expenses {
+		if !result.Rows[i].Duplicate {
+			toCreate = append(toCreate, expenses[i])
+			positions = append(positions, i)
+		}
+	}
+	if len(toCreate) == 0 {
+		return result, nil
+	}
+	categoryIDs := map[string]*uint{}
+	for i := range toCreate {
+		expense := &toCreate[i]
+		key := string(expense.Kind) + "/" + expense.Type
+		if id, ok := categoryIDs[key]; ok {
+			expense.CategoryID = id
+			continue
+		}
+		if err := s.assignCategory(expense); err != nil {
+			return nil, err
+		}
+		categoryIDs[key] = expense.CategoryID
+	}
+	err = s.inTx(func(tx *ExpenseService) error {
+		if err := tx.Repo.CreateBatch(toCreate); err != nil {
+			return err
+		}
+		revisions := make([]*dbmodel.ExpenseRevision, len(toCreate))
+		for i := range toCreate {
+			revisions[i] = newRevision(toCreate[i].ID, actorID, dbmodel.RevisionCreate, nil, stateOf(&toCreate[i]))
+		}
+		return tx.recordRevisions(revisions...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(toCreate)
+	for j, i := range positions {
+		result.Rows[i].Expense = toExpenseResponse(&toCreate[j])
+	}
+	return result, nil
+}
+
+// markDuplicates flags valid rows whose external ID already exists for the same
+// user and account, or appears earlier in the same file.
+func (s *ExpenseService) markDuplicates(userID uint, expenses []dbmodel.Expense, result *dto.ExpenseImportResult) error {
+	type accountKey struct {
+		set bool
+		id  uint
+	}
+	byAccount := map[accountKey][]int{}
+	for i, e := range expenses {
+		if e.ExternalID == nil || result.Rows[i].Error != "" {
+			continue
+		}
+		key := accountKey{}
+		if e.AccountID != nil {
+			key = accountKey{set: true, id: *e.AccountID}
+		}
+		byAccount[key] = append(byAccount[key], i)
+	}
+	for key, positions := range byAccount {
+		ids := make([]string, len(positions))
+		for j, i := range positions {
+			ids[j] = *expenses[i].ExternalID
+		}
+		var accountID *uint
+		if key.set {
+			accountID = &key.id
+		}
+		existing, err := s.Repo.ExistingExternalIDs(userID, accountID, ids)
+		if err != nil {
+			return err
+		}
+		for _, i := range positions {
+			id := *expenses[i].ExternalID
+			if existing[id] {
+				result.Rows[i].Duplicate = true
+				result.Duplicates++
+			}
+			existing[id] = true
+		}
+	}
+	return nil
+}
+
+// checkImportRow resolves the account name of a row and validates the expense.
+// It returns the validation error message, or "" when the row is valid.
+func checkImportRow(expense *dbmodel.Expense, accountName string, accountsByName map[string]uint, validateAccount func(uint, *uint) error) string {
+	if accountName != "" {
+		id, ok := accountsByName[strings.ToLower(accountName)]
+		if !ok {
+			return fmt.Sprintf("account not found: %s", accountName)
+		}
+		expense.AccountID = &id
+	}
+	if expense.Kind != dbmodel.ExpenseKindExpense && expense.Kind != dbmodel.ExpenseKindIncome {
+		return "kind must be expense or income"
+	}
+	if len(expense.Type) > 32 {
+		return "type must be at most 32 characters"
+	}
+	if expense.ExternalID != nil && len(*expense.ExternalID) > 255 {
+		return "external_id must be at most 255 characters"
+	}
+	if err := validateNewExpense(expense, validateAccount); err != nil {
+		return err.Error()
+	}
+	return ""
 }
 
 func (s *ExpenseService) UpdateExpense(expense *dbmodel.Expense) error {
-	// Validate amount sign based on kind
-	if expense.Kind == dbmodel.ExpenseKindExpense && expense.Amount > 0 {
-		return errors.New("expense amount must be negative")
+	if expense.Kind == dbmodel.ExpenseKindTransfer || expense.TransferID != nil {
+		return errTransferKind
 	}
-	if expense.Kind == dbmodel.ExpenseKindIncome && expense.Amount < 0 {
-		return errors.New("income amount must be positive")
+	if err := money.ValidateCurrency(expense.Currency); err != nil {
+		return err
+	}
+	if err := ValidateAmount(expense.Kind, expense.Amount, expense.Currency); err != nil {
+		return err
+	}
+	if err := s.ValidateAccount(expense.UserID, expense.AccountID); err != nil {
+		return err
+	}
+	if err := s.prepareSplits(expense); err != nil {
+		return err
+	}
+	if err := s.assignCategory(expense); err != nil {
+		return err
 	}
 	return s.Repo.Update(expense)
 }
 
 // UpdateExpenseFields updates only the explicitly provided fields for an expense.
 // expense is the current DB state (used for validation of the final kind/amount).
-func (s *ExpenseService) UpdateExpenseFields(expense *dbmodel.Expense, fields map[string]interface{}) error {
-	// Validate final kind/amount sign
-	if expense.Kind == dbmodel.ExpenseKindExpense && expense.Amount > 0 {
-		return errors.New("expense amount must be negative")
+// A "tags" entry ([]string) replaces the expense's tags and a "splits" entry
+// ([]dbmodel.ExpenseSplit) its split lines; existing lines are re-validated when
+// the amount, kind or currency changes. When the category is not given but the type or
+// kind changes, the category follows the type. The change is recorded as a
+// revision made by actorID.
+func (s *ExpenseService) UpdateExpenseFields(expense *dbmodel.Expense, fields map[string]interface{}, actorID uint) error {
+	return s.updateExpenseFields(expense, fields, actorID, dbmodel.RevisionUpdate, nil)
+}
+
+// updateExpenseFields implements UpdateExpenseFields and RevertExpense.
+func (s *ExpenseService) updateExpenseFields(expense *dbmodel.Expense, fields map[string]interface{}, actorID uint, action dbmodel.RevisionAction, revertedTo *uint) error {
+	if expense.Kind == dbmodel.ExpenseKindTransfer || expense.TransferID != nil {
+		return errTransferKind
 	}
-	if expense.Kind == dbmodel.ExpenseKindIncome && expense.Amount < 0 {
-		return errors.New("income amount must be positive")
+	current, err := s.Repo.GetByID(expense.ID)
+	if err != nil {
+		return err
+	}
+	if _, ok := fields["currency"]; ok {
+		if err := money.ValidateCurrency(expense.Currency); err != nil {
+			return err
+		}
+	}
+	if err := ValidateAmount(expense.Kind, expense.Amount, expense.Currency); err != nil {
+		return err
+	}
+	if _, ok := fields["account_id"]; ok {
+		if err := s.ValidateAccount(expense.UserID, expense.AccountID); err != nil {
+			return err
+		}
+	}
+	splits, splitsChanged := fields["splits"].([]dbmodel.ExpenseSplit)
+	_, amountChanged := fields["amount"]
+	_, kindChanged := fields["kind"]
+	_, currencyChanged := fields["currency"]
+	if splitsChanged {
+		delete(fields, "splits")
+		expense.Splits = splits
+	}
+	if splitsChanged || ((amountChanged || kindChanged || currencyChanged) && len(expense.Splits) > 0) {
+		typ := expense.Type
+		if err := s.prepareSplits(expense); err != nil {
+			return err
+		}
+		if expense.Type != typ {
+			fields["type"] = expense.Type
+		}
+	}
+	_, categoryChanged := fields["category_id"]
+	_, typeChanged := fields["type"]
+	if categoryChanged || typeChanged || kindChanged {
+		if !categoryChanged {
+			expense.CategoryID = nil
+		}
+		if err := s.assignCategory(expense); err != nil {
+			return err
+		}
+		fields["category_id"] = expense.CategoryID
+		fields["type"] = expense.Type
+	}
+	var tags []dbmodel.Tag
+	if names, ok := fields["tags"].([]string); ok {
+		delete(fields, "tags")
+		var err error
+		if tags, err = s.resolveTags(expense.UserID, names); err != nil {
+			return err
+		}
+	}
+	if tags != nil {
+		expense.Tags = tags
 	}
-	return s.Repo.UpdateFields(expense.ID, fields)
+	return s.inTx(func(tx *ExpenseService) error {
+		var err error
+		if tags == nil && !splitsChanged {
+			err = tx.Repo.UpdateFields(expense.ID, fields)
+		} else {
+			err = tx.Repo.UpdateFieldsAndAssociations(expense, fields, tags, splits)
+		}
+		if err != nil {
+			return err
+		}
+		revision := newRevision(expense.ID, actorID, action, stateOf(current), stateOf(expense))
+		if revision != nil {
+			revision.RevertedTo = revertedTo
+		}
+		return tx.recordRevisions(revision)
+	})
+}
+
+// RevertExpense sets the tracked fields of the expense back to their state at
+// one of its revisions. The revert is recorded as a new revision by actorID.
+func (s *ExpenseService) RevertExpense(expense *dbmodel.Expense, revisionID, actorID uint) error {
+	revision, err := s.Repo.GetRevision(revisionID)
+	if err != nil || revision.ExpenseID != expense.ID {
+		return ErrRevisionNotFound
+	}
+	var state revisionState
+	if err := json.Unmarshal([]byte(revision.State), &state); err != nil {
+		return err
+	}
+	expense.Amount = state.Amount
+	expense.Currency = state.Currency
+	expense.Kind = dbmodel.ExpenseKind(state.Kind)
+	expense.Type = state.Type
+	expense.CategoryID = state.CategoryID
+	expense.AccountID = state.AccountID
+	expense.Description = state.Description
+	expense.Date = state.Date
+	fields := map[string]interface{}{
+		"amount":      state.Amount,
+		"currency":    state.Currency,
+		"kind":        state.Kind,
+		"type":        state.Type,
+		"category_id": state.CategoryID,
+		"account_id":  state.AccountID,
+		"tags":        state.Tags,
+		"splits":      toSplitModels(state.Splits),
+		"description": state.Description,
+		"date":        state.Date,
+	}
+	return s.updateExpenseFields(expense, fields, actorID, dbmodel.RevisionRevert, &revision.ID)
+}
+
+// ListRevisions returns the change history of an expense, oldest first.
+func (s *ExpenseService) ListRevisions(expenseID uint) ([]dbmodel.ExpenseRevision, error) {
+	return s.Repo.ListRevisions(expenseID)
+}
+
+// GetExpenseWithDeleted returns the expense even when it is in the trash.
+func (s *ExpenseService) GetExpenseWithDeleted(id uint) (*dbmodel.Expense, error) {
+	return s.Repo.GetWithDeletedByID(id)
 }
 
 func (s *ExpenseService) GetExpenseByID(id uint) (*dbmodel.Expense, error) {
@@ -62,24 +624,427 @@ func (s *ExpenseService) GetUniqueTypes(userID uint) ([]string, error) {
 	return s.Repo.GetUniqueTypes(userID)
 }
 
-func (s *ExpenseService) DeleteExpense(id uint) error {
-	return s.Repo.Delete(id)
+// DeleteExpense deletes an expense on behalf of actorID. Deleting either leg of
+// a transfer deletes the whole transfer, so the accounts never end up
+// unbalanced.
+func (s *ExpenseService) DeleteExpense(id, actorID uint) error {
+	expense, err := s.Repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if expense.TransferID != nil {
+		return s.DeleteTransfer(*expense.TransferID, actorID)
+	}
+	return s.inTx(func(tx *ExpenseService) error {
+		if err := tx.Repo.Delete(id); err != nil {
+			return err
+		}
+		return tx.recordRevisions(newRevision(id, actorID, dbmodel.RevisionDelete, stateOf(expense), nil))
+	})
+}
+
+// ListTrash returns one page of the soft-deleted expenses and their total count.
+func (s *ExpenseService) ListTrash(filter dto.TrashFilter) ([]dbmodel.Expense, int64, error) {
+	return s.Repo.ListDeleted(filter.UserID, filter.Page, filter.PageSize)
+}
+
+func (s *ExpenseService) GetDeletedExpense(id uint) (*dbmodel.Expense, error) {
+	return s.Repo.GetDeletedByID(id)
+}
+
+// RestoreExpense takes a deleted expense out of the trash on behalf of
+// actorID. Restoring either leg of a transfer restores the whole transfer.
+func (s *ExpenseService) RestoreExpense(expense *dbmodel.Expense, actorID uint) error {
+	err := s.inTx(func(tx *ExpenseService) error {
+		if err := tx.Repo.Restore(expense); err != nil {
+			return err
+		}
+		if expense.TransferID == nil {
+			return tx.recordRevisions(newRevision(expense.ID, actorID, dbmodel.RevisionRestore, nil, stateOf(expense)))
+		}
+		transfer, err := tx.Repo.GetTransferByID(*expense.TransferID)
+		if err != nil {
+			return err
+		}
+		return tx.recordRevisions(legRevisions(transfer.Legs, actorID, dbmodel.RevisionRestore)...)
+	})
+	if err != nil {
+		return err
+	}
+	expense.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+// PurgeExpense permanently deletes an expense from the trash, with the other
+// leg of a transfer and the files of its attachments.
+func (s *ExpenseService) PurgeExpense(ctx context.Context, id uint) error {
+	_, err := s.purge(ctx, []uint{id})
+	return err
 }
 
-func (s *ExpenseService) ListExpenses(filter dto.ExpenseFilter) ([]dbmodel.Expense, error) {
+// PurgeTrash permanently deletes the user's expenses (every user's for userID
+// 0) that were deleted before the given time and returns how many were purged.
+func (s *ExpenseService) PurgeTrash(ctx context.Context, userID uint, before time.Time) (int64, error) {
+	var total int64
+	for {
+		ids, err := s.Repo.DeletedIDs(userID, before, trashPurgeBatch)
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+		n, err := s.purge(ctx, ids)
+		total += n
+		if err != nil || n == 0 || len(ids) < trashPurgeBatch {
+			return total, err
+		}
+	}
+}
+
+// purge hard-deletes the expenses and then removes their attachment files. A
+// file that cannot be removed is only logged, since its record is gone.
+func (s *ExpenseService) purge(ctx context.Context, ids []uint) (int64, error) {
+	keys, n, err := s.Repo.PurgeDeleted(ids)
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		if s.Storage == nil {
+			break
+		}
+		if err := s.Storage.Delete(ctx, key); err != nil {
+			slog.Warn("failed to remove attachment content", "key", key, "error", err)
+		}
+	}
+	return n, nil
+}
+
+// revisionState is the part of an expense that revisions track and revert.
+type revisionState struct {
+	Amount      decimal.Decimal           `json:"amount"`
+	Currency    string                    `json:"currency"`
+	Kind        string                    `json:"kind"`
+	Type        string                    `json:"type"`
+	CategoryID  *uint                     `json:"category_id"`
+	AccountID   *uint                     `json:"account_id"`
+	Tags        []string                  `json:"tags"`
+	Splits      []dto.ExpenseSplitRequest `json:"splits"`
+	Description string                    `json:"description"`
+	Date        string                    `json:"date"`
+}
+
+func stateOf(e *dbmodel.Expense) *revisionState {
+	tags := toTagNames(e.Tags)
+	sort.Strings(tags)
+	splits := make([]dto.ExpenseSplitRequest, len(e.Splits))
+	for i, line := range e.Splits {
+		splits[i] = dto.ExpenseSplitRequest{
+			Amount:      line.Amount,
+			Type:        line.Type,
+			CategoryID:  line.CategoryID,
+			Description: line.Description,
+		}
+	}
+	return &revisionState{
+		Amount:      e.Amount,
+		Currency:    e.Currency,
+		Kind:        string(e.Kind),
+		Type:        e.Type,
+		CategoryID:  e.CategoryID,
+		AccountID:   e.AccountID,
+		Tags:        tags,
+		Splits:      splits,
+		Description: e.Description,
+		Date:        e.Date,
+	}
+}
+
+// fields returns the JSON encoding of each tracked field. A nil state has the
+// same fields, all null.
+func (st *revisionState) fields() map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if st != nil {
+		b, _ := json.Marshal(st)
+		json.Unmarshal(b, &fields)
+		return fields
+	}
+	b, _ := json.Marshal(revisionState{})
+	json.Unmarshal(b, &fields)
+	for name := range fields {
+		fields[name] = json.RawMessage("null")
+	}
+	return fields
+}
+
+// newRevision builds the revision of an expense going from state before to
+// state after; before is nil for a create or restore and after for a delete.
+// It returns nil when no tracked field changed.
+func newRevision(expenseID, actorID uint, action dbmodel.RevisionAction, before, after *revisionState) *dbmodel.ExpenseRevision {
+	oldFields, newFields := before.fields(), after.fields()
+	changes := map[string]dto.ExpenseFieldChange{}
+	for name, value := range newFields {
+		if !bytes.Equal(oldFields[name], value) {
+			changes[name] = dto.ExpenseFieldChange{Old: oldFields[name], New: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	state := after
+	if state == nil {
+		state = before
+	}
+	changesJSON, _ := json.Marshal(changes)
+	stateJSON, _ := json.Marshal(state)
+	revision := &dbmodel.ExpenseRevision{
+		ExpenseID: expenseID,
+		Action:    action,
+		Changes:   string(changesJSON),
+		State:     string(stateJSON),
+	}
+	if actorID != 0 {
+		revision.ActorID = &actorID
+	}
+	return revision
+}
+
+// legRevisions builds the revisions of a transfer's legs being created,
+// deleted or restored together.
+func legRevisions(legs []dbmodel.Expense, actorID uint, action dbmodel.RevisionAction) []*dbmodel.ExpenseRevision {
+	revisions := make([]*dbmodel.ExpenseRevision, len(legs))
+	for i := range legs {
+		state := stateOf(&legs[i])
+		if action == dbmodel.RevisionDelete {
+			revisions[i] = newRevision(legs[i].ID, actorID, action, state, nil)
+		} else {
+			revisions[i] = newRevision(legs[i].ID, actorID, action, nil, state)
+		}
+	}
+	return revisions
+}
+
+// recordRevisions writes the revisions, skipping nil ones.
+func (s *ExpenseService) recordRevisions(revisions ...*dbmodel.ExpenseRevision) error {
+	rows := make([]dbmodel.ExpenseRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		if revision != nil {
+			rows = append(rows, *revision)
+		}
+	}
+	return s.Repo.CreateRevisions(rows)
+}
+
+// inTx runs fn with a copy of the service bound to a transaction, so that a
+// change and its revisions are written together.
+func (s *ExpenseService) inTx(fn func(tx *ExpenseService) error) error {
+	return s.Repo.DB.Transaction(func(db *gorm.DB) error {
+		return fn(s.withDB(db))
+	})
+}
+
+// errBulkRolledBack aborts the transaction of an atomic bulk with a failed item.
+var errBulkRolledBack = errors.New("bulk rolled back")
+
+// withDB returns a copy of the service whose repositories use db, so that
+// service methods can run inside a transaction.
+func (s *ExpenseService) withDB(db *gorm.DB) *ExpenseService {
+	tx := *s
+	tx.Repo = NewExpenseRepository(db)
+	tx.AccountRepo = account.NewAccountRepository(db)
+	tx.CategoryRepo = category.NewCategoryRepository(db)
+	return &tx
+}
+
+// Bulk runs fn for items 0..n-1 in one transaction and returns the error of
+// each item. fn gets a service bound to the transaction. Every item runs in its
+// own savepoint, so a failed item leaves nothing behind and the following
+// items still run. In atomic mode a failed item rolls back the whole
+// transaction, which is reported by rolledBack.
+func (s *ExpenseService) Bulk(n int, atomic bool, fn func(tx *ExpenseService, i int) error) (errs []error, rolledBack bool, err error) {
+	errs = make([]error, n)
+	err = s.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		failed := false
+		for i := 0; i < n; i++ {
+			errs[i] = tx.Transaction(func(sp *gorm.DB) error {
+				return fn(s.withDB(sp), i)
+			})
+			failed = failed || errs[i] != nil
+		}
+		if atomic && failed {
+			return errBulkRolledBack
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkRolledBack) {
+		return errs, true, nil
+	}
+	return errs, false, err
+}
+
+// CreateTransfer moves money between two accounts of the same user. It writes
+// a debit leg on the source account and a credit leg on the destination account
+// atomically. When the currencies differ and ToAmount is not given, the credited
+// amount is converted at the current exchange rate.
+func (s *ExpenseService) CreateTransfer(req dto.TransferCreateRequest, actorID uint) (*dbmodel.Transfer, error) {
+	if req.FromAccountID == req.ToAccountID {
+		return nil, errors.New("source and destination accounts must differ")
+	}
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("transfer amount must be positive")
+	}
+	if req.ToAmount.IsNegative() {
+		return nil, errors.New("to_amount must be positive")
+	}
+	from, err := s.activeAccount(req.UserID, req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("source %w", err)
+	}
+	to, err := s.activeAccount(req.UserID, req.ToAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("destination %w", err)
+	}
+
+	fromCurrency := req.Currency
+	if fromCurrency == "" {
+		fromCurrency = from.Currency
+	}
+	toCurrency := req.ToCurrency
+	if toCurrency == "" {
+		toCurrency = to.Currency
+	}
+	if err := money.Validate(req.Amount, fromCurrency); err != nil {
+		return nil, err
+	}
+	toAmount := req.ToAmount
+	if toAmount.IsZero() {
+		toAmount = req.Amount
+		if fromCurrency != toCurrency {
+			rates := s.Rates.Live.GetRates()
+			fromRate, toRate := rates[fromCurrency], rates[toCurrency]
+			if fromRate == 0 || toRate == 0 {
+				return nil, fmt.Errorf("no exchange rate for %s to %s, to_amount is required", fromCurrency, toCurrency)
+			}
+			toAmount = money.Convert(req.Amount, decimal.NewFromFloat(fromRate), decimal.NewFromFloat(toRate), toCurrency)
+		}
+	} else if err := money.Validate(toAmount, toCurrency); err != nil {
+		return nil, err
+	}
+
+	transfer := &dbmodel.Transfer{
+		UserID:        req.UserID,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Description:   req.Description,
+		Date:          req.Date,
+	}
+	debit := dbmodel.Expense{
+		UserID:      req.UserID,
+		Amount:      req.Amount.Neg(),
+		Currency:    fromCurrency,
+		Kind:        dbmodel.ExpenseKindTransfer,
+		AccountID:   &from.ID,
+		Description: req.Description,
+		Date:        req.Date,
+	}
+	credit := dbmodel.Expense{
+		UserID:      req.UserID,
+		Amount:      toAmount,
+		Currency:    toCurrency,
+		Kind:        dbmodel.ExpenseKindTransfer,
+		AccountID:   &to.ID,
+		Description: req.Description,
+		Date:        req.Date,
+	}
+	err = s.inTx(func(tx *ExpenseService) error {
+		if err := tx.Repo.CreateTransfer(transfer, []*dbmodel.Expense{&debit, &credit}); err != nil {
+			return err
+		}
+		return tx.recordRevisions(legRevisions([]dbmodel.Expense{debit, credit}, actorID, dbmodel.RevisionCreate)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	transfer.Legs = []dbmodel.Expense{debit, credit}
+	return transfer, nil
+}
+
+func (s *ExpenseService) GetTransferByID(id uint) (*dbmodel.Transfer, error) {
+	return s.Repo.GetTransferByID(id)
+}
+
+// DeleteTransfer deletes the transfer and both of its legs on behalf of actorID.
+func (s *ExpenseService) DeleteTransfer(id, actorID uint) error {
+	transfer, err := s.Repo.GetTransferByID(id)
+	if err != nil {
+		return err
+	}
+	return s.inTx(func(tx *ExpenseService) error {
+		if err := tx.Repo.DeleteTransfer(id); err != nil {
+			return err
+		}
+		return tx.recordRevisions(legRevisions(transfer.Legs, actorID, dbmodel.RevisionDelete)...)
+	})
+}
+
+// ListExpenses returns one page of expenses and the cursor of the next page.
+func (s *ExpenseService) ListExpenses(filter dto.ExpenseFilter) ([]dbmodel.Expense, string, error) {
 	return s.Repo.ListByFilter(filter)
 }
 
+// CountExpenses returns how many expenses match filter, ignoring pagination.
+func (s *ExpenseService) CountExpenses(filter dto.ExpenseFilter) (int64, error) {
+	return s.Repo.CountByFilter(filter)
+}
+
+// Highlights returns the highlighted descriptions of the expenses that match
+// the search text, keyed by expense ID.
+func (s *ExpenseService) Highlights(expenses []dbmodel.Expense, text string) (map[uint]string, error) {
+	ids := make([]uint, len(expenses))
+	for i, e := range expenses {
+		ids[i] = e.ID
+	}
+	return s.Repo.Highlights(ids, text)
+}
+
 func (s *ExpenseService) AggregateMeta(filter dto.ExpenseFilter) (total, incomeCount, expenseCount int, byCurrency map[string]*dto.CurrencySummary, err error) {
 	return s.Repo.AggregateMeta(filter)
 }
 
+// Summary returns the totals of the expenses matching filter. With Compare it
+// also summarizes the comparison window and adds the deltas.
 func (s *ExpenseService) Summary(filter dto.SummaryFilter) (*dto.ExpenseSummary, error) {
+	var prevFrom, prevTo string
+	if filter.Compare != "" {
+		var err error
+		if prevFrom, prevTo, err = comparisonWindow(filter.Compare, filter.From, filter.To); err != nil {
+			return &dto.ExpenseSummary{}, err
+		}
+	}
+	summary, err := s.summarize(filter)
+	if err != nil || filter.Compare == "" {
+		return summary, err
+	}
+	prevFilter := filter
+	prevFilter.From, prevFilter.To = prevFrom, prevTo
+	previous, err := s.summarize(prevFilter)
+	if err != nil {
+		return &dto.ExpenseSummary{}, err
+	}
+	summary.Comparison = compareSummaries(summary, previous)
+	summary.Comparison.Compare = filter.Compare
+	summary.Comparison.From, summary.Comparison.To = prevFrom, prevTo
+	return summary, nil
+}
+
+// summarize computes the summary of one window.
+func (s *ExpenseService) summarize(filter dto.SummaryFilter) (*dto.ExpenseSummary, error) {
 	listFilter := dto.ExpenseFilter{
 		UserID:     filter.UserID,
 		Kind:       filter.Kind,
 		Types:      filter.Types,
 		Currencies: filter.Currencies,
+		AccountIDs: filter.AccountIDs,
+		Tags:       filter.Tags,
+		TagsMode:   filter.TagsMode,
+		Q:          filter.Q,
 		From:       filter.From,
 		To:         filter.To,
 	}
@@ -93,51 +1058,255 @@ func (s *ExpenseService) Summary(filter dto.SummaryFilter) (*dto.ExpenseSummary,
 		originalCurrency = "VND"
 	}
 
-	summary := s.computeSummary(expenses, originalCurrency)
+	roots, err := s.categoryRoots(filter.UserID)
+	if err != nil {
+		return &dto.ExpenseSummary{}, err
+	}
+
+	mode := filter.RateMode
+	if mode == "" {
+		mode = dto.RateModeHistorical
+	}
+	dated := make([]datedCurrency, len(expenses))
+	for i := range expenses {
+		dated[i] = datedCurrency{expenses[i].Currency, expenses[i].Date}
+	}
+	currencies, from, to := rateRange(dated)
+	conv, err := s.Rates.NewConverter(originalCurrency, mode, currencies, from, to)
+	if err != nil {
+		return &dto.ExpenseSummary{}, err
+	}
+
+	summary := s.computeSummary(expenses, filter.Types, conv, roots)
+	summary.RateMode = mode
 	return summary, nil
 }
 
-func (s *ExpenseService) computeSummary(expenses []dbmodel.Expense, targetCurrency string) *dto.ExpenseSummary {
-	var totalIncome, totalExpense float64
-	var incomeCount, expenseCount int
-	totalByTypeIncome := make(map[string]float64)
-	totalByTypeExpense := make(map[string]float64)
+// comparisonWindow returns the window to compare from..to with. The previous
+// period of whole calendar months is the same number of months before from;
+// any other window is shifted back by its length in days.
+func comparisonWindow(compare, from, to string) (string, string, error) {
+	if from == "" || to == "" {
+		return "", "", fmt.Errorf("%w: compare needs from and to", ErrInvalidComparison)
+	}
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: invalid from date %q", ErrInvalidComparison, from)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: invalid to date %q", ErrInvalidComparison, to)
+	}
+	if end.Before(start) {
+		return "", "", fmt.Errorf("%w: to is before from", ErrInvalidComparison)
+	}
+	switch compare {
+	case dto.ComparePreviousYear:
+		return yearEarlier(start).Format("2006-01-02"), yearEarlier(end).Format("2006-01-02"), nil
+	case dto.ComparePreviousPeriod:
+		if start.Day() == 1 && end.AddDate(0, 0, 1).Day() == 1 {
+			months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+			return start.AddDate(0, -months, 0).Format("2006-01-02"), start.AddDate(0, 0, -1).Format("2006-01-02"), nil
+		}
+		days := int(end.Sub(start).Hours()/24) + 1
+		return start.AddDate(0, 0, -days).Format("2006-01-02"), start.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	return "", "", fmt.Errorf("%w: unsupported compare %q", ErrInvalidComparison, compare)
+}
+
+// yearEarlier returns the same day a year before t; 29 February becomes 28
+// February.
+func yearEarlier(t time.Time) time.Time {
+	earlier := t.AddDate(-1, 0, 0)
+	if earlier.Month() != t.Month() {
+		earlier = earlier.AddDate(0, 0, -earlier.Day())
+	}
+	return earlier
+}
+
+// compareSummaries returns the deltas of current against previous, overall
+// and per type. A type missing from one window counts as 0 there.
+func compareSummaries(current, previous *dto.ExpenseSummary) *dto.SummaryComparison {
+	byType := func(cur, prev map[string]decimal.Decimal) map[string]dto.SummaryDelta {
+		deltas := make(map[string]dto.SummaryDelta)
+		for typ, amount := range cur {
+			deltas[typ] = summaryDelta(amount, prev[typ], false)
+		}
+		for typ, amount := range prev {
+			if _, ok := cur[typ]; !ok {
+				deltas[typ] = summaryDelta(decimal.Zero, amount, false)
+			}
+		}
+		return deltas
+	}
+	return &dto.SummaryComparison{
+		Previous:           previous,
+		TotalIncome:        summaryDelta(current.TotalIncome, previous.TotalIncome, false),
+		TotalExpense:       summaryDelta(current.TotalExpense, previous.TotalExpense, false),
+		TotalBalance:       summaryDelta(current.TotalBalance, previous.TotalBalance, true),
+		TotalByTypeIncome:  byType(current.TotalByTypeIncome, previous.TotalByTypeIncome),
+		TotalByTypeExpense: byType(current.TotalByTypeExpense, previous.TotalByTypeExpense),
+	}
+}
+
+// summaryDelta compares two values of a total. Income and expense totals keep
+// their sign, so their percent is Current/Previous - 1: the change of the
+// magnitude, positive when more was spent. A balance can have either sign, so
+// mixedSign measures Change/|Previous|: positive when the balance improved.
+func summaryDelta(current, previous decimal.Decimal, mixedSign bool) dto.SummaryDelta {
+	delta := dto.SummaryDelta{Current: current, Previous: previous, Change: current.Sub(previous)}
+	if previous.IsZero() {
+		return delta
+	}
+	ratio := current.Div(previous).Sub(decimal.NewFromInt(1))
+	if mixedSign {
+		ratio = delta.Change.Div(previous.Abs())
+	}
+	percent := ratio.Shift(2).Round(2).InexactFloat64()
+	delta.Percent = &percent
+	return delta
+}
+
+// datedCurrency is the currency and date of a row to be converted.
+type datedCurrency struct {
+	Currency string
+	Date     string
+}
+
+// rateRange returns the distinct currencies of the rows and the range of
+// their dates, i.e. the rate snapshots needed to convert them.
+func rateRange(rows []datedCurrency) (currencies []string, from, to string) {
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if !seen[row.Currency] {
+			seen[row.Currency] = true
+			currencies = append(currencies, row.Currency)
+		}
+		if from == "" || row.Date < from {
+			from = row.Date
+		}
+		if row.Date > to {
+			to = row.Date
+		}
+	}
+	return
+}
+
+// categoryRoots maps the IDs of the categories visible to userID to their
+// top-level category.
+func (s *ExpenseService) categoryRoots(userID uint) (map[uint]uint, error) {
+	categories, err := s.CategoryRepo.ListVisible(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	return category.RootIDs(categories), nil
+}
+
+// addCategoryTotal adds amount to the leaf category and to its top-level
+// category. Uncategorised amounts are skipped.
+func addCategoryTotal(byCategory, byParent map[uint]decimal.Decimal, roots map[uint]uint, categoryID *uint, amount decimal.Decimal) {
+	if categoryID == nil {
+		return
+	}
+	byCategory[*categoryID] = byCategory[*categoryID].Add(amount)
+	root, ok := roots[*categoryID]
+	if !ok {
+		root = *categoryID
+	}
+	byParent[root] = byParent[root].Add(amount)
+}
+
+// matchingLines returns the split lines whose type is in types and their sum.
+func matchingLines(lines []dbmodel.ExpenseSplit, types []string) ([]dbmodel.ExpenseSplit, decimal.Decimal) {
+	var matched []dbmodel.ExpenseSplit
+	var sum decimal.Decimal
+	for _, line := range lines {
+		if slices.Contains(types, line.Type) {
+			matched = append(matched, line)
+			sum = sum.Add(line.Amount)
+		}
+	}
+	return matched, sum
+}
+
+// computeSummary totals the expenses. With types, a split expense only counts
+// with its lines of those types, matching the type filter of the query.
+func (s *ExpenseService) computeSummary(expenses []dbmodel.Expense, types []string, conv *currency.Converter, roots map[uint]uint) *dto.ExpenseSummary {
+	var totalIncome, totalExpense decimal.Decimal
+	var incomeCount, expenseCount, transferCount int
+	totalByTypeIncome := make(map[string]decimal.Decimal)
+	totalByTypeExpense := make(map[string]decimal.Decimal)
+	totalByCategory := make(map[uint]decimal.Decimal)
+	totalByParentCategory := make(map[uint]decimal.Decimal)
 	byCurrency := make(map[string]*dto.CurrencySummary)
+	byAccount := make(map[uint]*dto.CurrencySummary)
 
-	exchangeRates := currency.GetExchangeRateService().GetRates()
-	targetRate := exchangeRates[targetCurrency]
-	if targetRate == 0 {
-		targetRate = 1
+	// attribute adds an expense to the type and category totals; a split
+	// expense is attributed to its lines rather than to its own type.
+	attribute := func(byType map[string]decimal.Decimal, expense *dbmodel.Expense) {
+		if len(expense.Splits) == 0 {
+			converted := conv.Convert(expense.Amount, expense.Currency, expense.Date)
+			byType[expense.Type] = byType[expense.Type].Add(converted)
+			addCategoryTotal(totalByCategory, totalByParentCategory, roots, expense.CategoryID, converted)
+			return
+		}
+		for _, line := range expense.Splits {
+			converted := conv.Convert(line.Amount, expense.Currency, expense.Date)
+			byType[line.Type] = byType[line.Type].Add(converted)
+			addCategoryTotal(totalByCategory, totalByParentCategory, roots, line.CategoryID, converted)
+		}
 	}
 
 	for _, expense := range expenses {
-		rate := exchangeRates[expense.Currency]
-		if rate == 0 {
-			rate = 1
+		if len(types) > 0 && len(expense.Splits) > 0 {
+			expense.Splits, expense.Amount = matchingLines(expense.Splits, types)
+			if len(expense.Splits) == 0 {
+				continue
+			}
 		}
-		converted := expense.Amount * rate / targetRate
+		converted := conv.Convert(expense.Amount, expense.Currency, expense.Date)
 
 		// Per-currency native amounts (not converted)
 		if _, ok := byCurrency[expense.Currency]; !ok {
 			byCurrency[expense.Currency] = &dto.CurrencySummary{}
 		}
 
-		if expense.Kind == dbmodel.ExpenseKindIncome {
+		// Per-account amounts, converted to the target currency
+		acc := &dto.CurrencySummary{}
+		if expense.AccountID != nil {
+			if _, ok := byAccount[*expense.AccountID]; !ok {
+				byAccount[*expense.AccountID] = &dto.CurrencySummary{}
+			}
+			acc = byAccount[*expense.AccountID]
+		}
+
+		switch expense.Kind {
+		case dbmodel.ExpenseKindIncome:
 			incomeCount++
-			totalIncome += converted
-			totalByTypeIncome[expense.Type] += converted
-			byCurrency[expense.Currency].TotalIncome += expense.Amount
-		} else {
+			totalIncome = totalIncome.Add(converted)
+			attribute(totalByTypeIncome, &expense)
+			byCurrency[expense.Currency].TotalIncome = byCurrency[expense.Currency].TotalIncome.Add(expense.Amount)
+			acc.TotalIncome = acc.TotalIncome.Add(converted)
+		case dbmodel.ExpenseKindTransfer:
+			// Transfers only move money between accounts.
+			transferCount++
+			byCurrency[expense.Currency].TotalTransfer = byCurrency[expense.Currency].TotalTransfer.Add(expense.Amount)
+			acc.TotalTransfer = acc.TotalTransfer.Add(converted)
+		default:
 			expenseCount++
-			totalExpense += converted
-			totalByTypeExpense[expense.Type] += converted
-			byCurrency[expense.Currency].TotalExpense += expense.Amount
+			totalExpense = totalExpense.Add(converted)
+			attribute(totalByTypeExpense, &expense)
+			byCurrency[expense.Currency].TotalExpense = byCurrency[expense.Currency].TotalExpense.Add(expense.Amount)
+			acc.TotalExpense = acc.TotalExpense.Add(converted)
 		}
 	}
 
-	// Compute per-currency balance
+	// Compute per-currency and per-account balance
 	for _, cs := range byCurrency {
-		cs.TotalBalance = cs.TotalIncome + cs.TotalExpense
+		cs.TotalBalance = cs.TotalIncome.Add(cs.TotalExpense).Add(cs.TotalTransfer)
+	}
+	for _, cs := range byAccount {
+		cs.TotalBalance = cs.TotalIncome.Add(cs.TotalExpense).Add(cs.TotalTransfer)
 	}
 
 	// Only include ByCurrency when there are multiple currencies
@@ -145,22 +1314,81 @@ func (s *ExpenseService) computeSummary(expenses []dbmodel.Expense, targetCurren
 	if len(byCurrency) > 1 {
 		byCurrencyResult = byCurrency
 	}
+	var byAccountResult map[uint]*dto.CurrencySummary
+	if len(byAccount) > 0 {
+		byAccountResult = byAccount
+	}
 
 	return &dto.ExpenseSummary{
-		Currency:           targetCurrency,
-		IncomeCount:        incomeCount,
-		ExpenseCount:       expenseCount,
-		TotalIncome:        totalIncome,
-		TotalExpense:       totalExpense,
-		TotalBalance:       totalIncome + totalExpense,
-		TotalByTypeIncome:  totalByTypeIncome,
-		TotalByTypeExpense: totalByTypeExpense,
-		ByCurrency:         byCurrencyResult,
+		Currency:              conv.Target,
+		IncomeCount:           incomeCount,
+		ExpenseCount:          expenseCount,
+		TransferCount:         transferCount,
+		TotalIncome:           totalIncome,
+		TotalExpense:          totalExpense,
+		TotalBalance:          totalIncome.Add(totalExpense),
+		TotalByTypeIncome:     totalByTypeIncome,
+		TotalByTypeExpense:    totalByTypeExpense,
+		TotalByCategory:       totalByCategory,
+		TotalByParentCategory: totalByParentCategory,
+		ByCurrency:            byCurrencyResult,
+		ByAccount:             byAccountResult,
 	}
 }
 
+// ExportExpenses writes every expense matching filter (pagination is ignored)
+// to w. When convertTo is set, an extra column holds the amount converted to
+// that currency at the current exchange rate, or at a manual override that
+// applies today.
+func (s *ExpenseService) ExportExpenses(filter dto.ExpenseFilter, convertTo string, newWriter func(columns []string) (exporter.Writer, error)) error {
+	conv, err := s.Rates.NewConverter(convertTo, dto.RateModeCurrent, nil, "", "")
+	if err != nil {
+		return err
+	}
+	if convertTo != "" && !conv.HasLiveRate(convertTo) {
+		return fmt.Errorf("%w: %s", ErrUnknownCurrency, convertTo)
+	}
+
+	accounts, err := s.AccountRepo.ListByUser(filter.UserID, true)
+	if err != nil {
+		return err
+	}
+	accountNames := make(map[uint]string, len(accounts))
+	for _, acc := range accounts {
+		accountNames[acc.ID] = acc.Name
+	}
+
+	columns := []string{"id", "date", "kind", "type", "amount", "currency", "account", "description"}
+	if convertTo != "" {
+		columns = append(columns, "amount_"+strings.ToLower(convertTo))
+	}
+	w, err := newWriter(columns)
+	if err != nil {
+		return err
+	}
+	err = s.Repo.StreamByFilter(filter, func(e *dbmodel.Expense) error {
+		account := ""
+		if e.AccountID != nil {
+			account = accountNames[*e.AccountID]
+		}
+		values := []interface{}{e.ID, e.Date, string(e.Kind), e.Type, e.Amount, e.Currency, account, e.Description}
+		if convertTo != "" {
+			values = append(values, conv.Convert(e.Amount, e.Currency, e.Date))
+		}
+		return w.WriteRow(values)
+	})
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // Groups aggregates expenses into time-bucket groups with Go-side sort and pagination.
-// Currency conversion uses live rates; sort/paginate in Go so computed fields are accurate.
+// Amounts are converted at the rate of their own date (rate_mode=historical, the
+// default), from the stored snapshots and manual overrides, or all at today's rate
+// with rate_mode=current. Clients of Groups and Summary that predate rate modes
+// got today's rates and now get historical ones unless they ask for current.
+// Sort/paginate in Go so computed fields are accurate.
 func (s *ExpenseService) Groups(filter dto.GroupsFilter) (*dto.ExpenseGroupsResponse, error) {
 	aggRows, err := s.Repo.ListGroupsAggByFilter(filter)
 	if err != nil {
@@ -172,49 +1400,72 @@ func (s *ExpenseService) Groups(filter dto.GroupsFilter) (*dto.ExpenseGroupsResp
 		originalCurrency = "VND"
 	}
 
-	exchangeRates := currency.GetExchangeRateService().GetRates()
-	targetRate := exchangeRates[originalCurrency]
-	if targetRate == 0 {
-		targetRate = 1
+	roots, err := s.categoryRoots(filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := filter.RateMode
+	if mode == "" {
+		mode = dto.RateModeHistorical
+	}
+	dated := make([]datedCurrency, len(aggRows))
+	for i, row := range aggRows {
+		dated[i] = datedCurrency{row.Currency, row.Date}
+	}
+	currencies, from, to := rateRange(dated)
+	conv, err := s.Rates.NewConverter(originalCurrency, mode, currencies, from, to)
+	if err != nil {
+		return nil, err
 	}
 
 	type agg struct {
-		Income      float64
-		Expense     float64
-		TotalByType map[string]float64
+		Income                decimal.Decimal
+		Expense               decimal.Decimal
+		TotalByType           map[string]decimal.Decimal
+		TotalByCategory       map[uint]decimal.Decimal
+		TotalByParentCategory map[uint]decimal.Decimal
 	}
 	groupMap := make(map[string]*agg)
 	keyOrder := make([]string, 0)
 
 	for _, row := range aggRows {
+		// Transfers move money between accounts and are neither income nor expense.
+		if row.Kind == string(dbmodel.ExpenseKindTransfer) {
+			continue
+		}
 		if groupMap[row.Bucket] == nil {
-			groupMap[row.Bucket] = &agg{TotalByType: make(map[string]float64)}
+			groupMap[row.Bucket] = &agg{
+				TotalByType:           make(map[string]decimal.Decimal),
+				TotalByCategory:       make(map[uint]decimal.Decimal),
+				TotalByParentCategory: make(map[uint]decimal.Decimal),
+			}
 			keyOrder = append(keyOrder, row.Bucket)
 		}
-		rate := exchangeRates[row.Currency]
-		if rate == 0 {
-			rate = 1
-		}
-		converted := row.Total * rate / targetRate
-		groupMap[row.Bucket].TotalByType[row.Type] += converted
+		g := groupMap[row.Bucket]
+		converted := conv.Convert(row.Total, row.Currency, row.Date)
+		g.TotalByType[row.Type] = g.TotalByType[row.Type].Add(converted)
+		addCategoryTotal(g.TotalByCategory, g.TotalByParentCategory, roots, row.CategoryID, converted)
 		if row.Kind == string(dbmodel.ExpenseKindIncome) {
-			groupMap[row.Bucket].Income += converted
+			g.Income = g.Income.Add(converted)
 		} else {
-			groupMap[row.Bucket].Expense += converted
+			g.Expense = g.Expense.Add(converted)
 		}
 	}
 
-	mode := strings.ToUpper(filter.GroupBy)
+	groupBy := strings.ToUpper(filter.GroupBy)
 	groups := make([]dto.ExpenseGroup, 0, len(keyOrder))
 	for _, k := range keyOrder {
 		g := groupMap[k]
 		groups = append(groups, dto.ExpenseGroup{
-			Key:         k,
-			Label:       bucketLabel(k, mode),
-			Income:      g.Income,
-			Expense:     g.Expense,
-			Balance:     g.Income + g.Expense,
-			TotalByType: g.TotalByType,
+			Key:                   k,
+			Label:                 bucketLabel(k, groupBy),
+			Income:                g.Income,
+			Expense:               g.Expense,
+			Balance:               g.Income.Add(g.Expense),
+			TotalByType:           g.TotalByType,
+			TotalByCategory:       g.TotalByCategory,
+			TotalByParentCategory: g.TotalByParentCategory,
 		})
 	}
 
@@ -230,11 +1481,11 @@ func (s *ExpenseService) Groups(filter dto.GroupsFilter) (*dto.ExpenseGroupsResp
 			var less bool
 			switch orderBy {
 			case "income":
-				less = groups[i].Income < groups[j].Income
+				less = groups[i].Income.LessThan(groups[j].Income)
 			case "expense":
-				less = groups[i].Expense < groups[j].Expense
+				less = groups[i].Expense.LessThan(groups[j].Expense)
 			case "balance":
-				less = groups[i].Balance < groups[j].Balance
+				less = groups[i].Balance.LessThan(groups[j].Balance)
 			default: // "period"
 				less = groups[i].Key < groups[j].Key
 			}
@@ -270,6 +1521,7 @@ func (s *ExpenseService) Groups(filter dto.GroupsFilter) (*dto.ExpenseGroupsResp
 	}
 
 	return &dto.ExpenseGroupsResponse{
+		RateMode: mode,
 		Total:    total,
 		Page:     page,
 		PageSize: pageSize,
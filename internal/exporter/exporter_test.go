@@ -0,0 +1,80 @@
+package exporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Grab to Dalat", "Grab to Dalat"},
+		{`=HYPERLINK("http://x","click")`, `'=HYPERLINK("http://x","click")`},
+		{"+84901234567", "'+84901234567"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSVEscapesStringsOnly(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, []string{"amount", "description"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{decimal.RequireFromString("-150000"), "=1+1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "amount,description\n-150000,'=1+1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("csv = %q, want %q", got, want)
+	}
+}
+
+func TestXLSXEscapesStringsOnly(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, []string{"amount", "description"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{decimal.RequireFromString("-150000"), "=HYPERLINK(\"http://x\")"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if formula, _ := f.GetCellFormula(xlsxSheet, "B2"); formula != "" {
+		t.Errorf("B2 has formula %q", formula)
+	}
+	if got, _ := f.GetCellValue(xlsxSheet, "B2"); got != "'=HYPERLINK(\"http://x\")" {
+		t.Errorf("B2 = %q", got)
+	}
+	if typ, _ := f.GetCellType(xlsxSheet, "A2"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Errorf("A2 is text, want a number")
+	}
+	if got, _ := f.GetCellValue(xlsxSheet, "A2"); got != "-150000" {
+		t.Errorf("A2 = %q, want -150000", got)
+	}
+}
@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Options describes how to read a file. Mapping and Delimiter only apply to
+// CSV and Bank only to statements; OFX is self-describing.
+type Options struct {
+	// Mapping assigns a field (see the Field constants) to a column, given
+	// either by its header name (case-insensitive) or by its 1-based number.
+	// date and amount are required.
+	Mapping map[string]string
+	// DateFormat is a pattern such as DD/MM/YYYY (default YYYY-MM-DD for CSV
+	// and MM/DD/YYYY for QIF).
+	DateFormat string
+	// DecimalSeparator is "." (default) or ",".
+	DecimalSeparator string
+	// Delimiter separates columns. When zero it is detected from the header line.
+	Delimiter rune
+	// Bank selects the statement parser; detected from the statement when empty.
+	Bank string
+}
+
+// ParseCSV reads a CSV file with a header line and returns one Row per data
+// line. Problems with a single line are reported in Row.Error; an error is
+// returned only when the file or the options as a whole are unusable.
+func ParseCSV(r io.Reader, opts Options) ([]Row, error) {
+	layout, err := DateLayout(opts.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+	decimal := opts.DecimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	if decimal != "." && decimal != "," {
+		return nil, errors.New("decimal separator must be \".\" or \",\"")
+	}
+
+	br := bufio.NewReader(r)
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		first, _ := br.Peek(4096)
+		delimiter = detectDelimiter(string(first))
+	}
+	reader := csv.NewReader(br)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	columns, err := resolveColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, Row{Line: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+		value := func(field string) string {
+			idx, ok := columns[field]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		row := Row{
+			Line:        line,
+			Kind:        value(FieldKind),
+			Type:        value(FieldType),
+			Currency:    value(FieldCurrency),
+			Description: value(FieldDescription),
+			Account:     value(FieldAccount),
+			ExternalID:  value(FieldExternalID),
+		}
+		var problems []string
+		if row.Date, err = ParseDate(value(FieldDate), layout); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if row.Amount, err = ParseAmount(value(FieldAmount), decimal); err != nil {
+			problems = append(problems, err.Error())
+		}
+		row.Error = strings.Join(problems, "; ")
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// resolveColumns maps each field of the mapping to a 0-based column index.
+func resolveColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	columns := make(map[string]int, len(mapping))
+	for field, column := range mapping {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !knownFields[field] {
+			return nil, fmt.Errorf("unknown field in mapping: %s", field)
+		}
+		idx := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(column)) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			if n, err := strconv.Atoi(strings.TrimSpace(column)); err == nil && n >= 1 && n <= len(header) {
+				idx = n - 1
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("column %q for %s not found in header", column, field)
+		}
+		columns[field] = idx
+	}
+	for _, required := range []string{FieldDate, FieldAmount} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("mapping for %s is required", required)
+		}
+	}
+	return columns, nil
+}
+
+// detectDelimiter picks the most frequent of , ; and tab in sample.
+func detectDelimiter(sample string) rune {
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := strings.Count(sample, string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
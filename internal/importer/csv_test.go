@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  []Row
+	}{
+		{
+			name: "mapping by header name",
+			input: "\ufeffDate,Amount,Kind,Category,Currency,Note,Wallet,Ref\n" +
+				"2024-03-01,-150000,expense,Food,VND,Grab,Cash,A1\n" +
+				"\n" +
+				"2024-03-02,\"1,500.50\",,,USD,\"Salary, March\",,\n",
+			opts: Options{Mapping: map[string]string{
+				"date": "date", "amount": "AMOUNT", "kind": "Kind", "type": "Category",
+				"currency": "Currency", "description": "Note", "account": "Wallet", "external_id": "Ref",
+			}},
+			want: []Row{
+				{Line: 2, Date: "2024-03-01", Amount: dec("-150000"), Kind: "expense", Type: "Food", Currency: "VND", Description: "Grab", Account: "Cash", ExternalID: "A1"},
+				{Line: 4, Date: "2024-03-02", Amount: dec("1500.5"), Currency: "USD", Description: "Salary, March"},
+			},
+		},
+		{
+			name: "semicolons, comma decimals and column numbers",
+			input: "Ngày;Số tiền;Mô tả\n" +
+				"15/03/2024;-1.234,50;Sách\n" +
+				"1/4/2024;(20,00);Phí\n",
+			opts: Options{
+				Mapping:          map[string]string{"date": "1", "amount": "2", "description": "3"},
+				DateFormat:       "DD/MM/YYYY",
+				DecimalSeparator: ",",
+			},
+			want: []Row{
+				{Line: 2, Date: "2024-03-15", Amount: dec("-1234.5"), Description: "Sách"},
+				{Line: 3, Date: "2024-04-01", Amount: dec("-20"), Description: "Phí"},
+			},
+		},
+		{
+			name:  "explicit delimiter",
+			input: "date|amount\n2024-01-05|10\n",
+			opts:  Options{Mapping: map[string]string{"date": "date", "amount": "amount"}, Delimiter: '|'},
+			want:  []Row{{Line: 2, Date: "2024-01-05", Amount: dec("10")}},
+		},
+		{
+			name:  "invalid rows are reported",
+			input: "date,amount\n2024-02-30,10\n2024-02-01,\n2024-02-01,abc\n",
+			opts:  Options{Mapping: map[string]string{"date": "date", "amount": "amount"}},
+			want:  []Row{{Line: 2}, {Line: 3}, {Line: 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseCSV(strings.NewReader(tt.input), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	dateAndAmount := map[string]string{"date": "date", "amount": "amount"}
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{"empty file", "", Options{Mapping: dateAndAmount}, "file is empty"},
+		{"unknown field", "date,amount\n", Options{Mapping: map[string]string{"date": "date", "amount": "amount", "payee": "date"}}, "unknown field"},
+		{"missing column", "date,value\n", Options{Mapping: dateAndAmount}, `column "amount" for amount not found`},
+		{"column number out of range", "date,amount\n", Options{Mapping: map[string]string{"date": "1", "amount": "3"}}, "not found"},
+		{"amount not mapped", "date,amount\n", Options{Mapping: map[string]string{"date": "date"}}, "mapping for amount is required"},
+		{"decimal separator", "date,amount\n", Options{Mapping: dateAndAmount, DecimalSeparator: "'"}, "decimal separator"},
+		{"date format", "date,amount\n", Options{Mapping: dateAndAmount, DateFormat: "day/month"}, "invalid date format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.input), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	tests := map[string]string{
+		"march.csv":       FormatCSV,
+		"export.OFX":      FormatOFX,
+		"card.qfx":        FormatOFX,
+		"quicken.qif":     FormatQIF,
+		"sao-ke-vcb.xlsx": FormatStatement,
+		"noextension":     FormatCSV,
+	}
+	for name, want := range tests {
+		if got := FormatFromFilename(name); got != want {
+			t.Errorf("FormatFromFilename(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// compareRows compares the parsed fields of rows with want; Error only has to
+// be non-empty when a row of want has no Date.
+func compareRows(t *testing.T, rows, want []Row) {
+	t.Helper()
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i, w := range want {
+		got := rows[i]
+		if w.Date == "" {
+			if got.Error == "" {
+				t.Errorf("row %d: expected an error, got %+v", i, got)
+			}
+			continue
+		}
+		if got.Error != "" {
+			t.Errorf("row %d: unexpected error %q", i, got.Error)
+		}
+		if got.Line != w.Line || got.Date != w.Date || !got.Amount.Equal(w.Amount) || got.Kind != w.Kind ||
+			got.Type != w.Type || got.Description != w.Description {
+			t.Errorf("row %d = %+v, want %+v", i, got, w)
+		}
+		if w.ExternalID != "" && got.ExternalID != w.ExternalID {
+			t.Errorf("row %d: external ID %q, want %q", i, got.ExternalID, w.ExternalID)
+		}
+		if w.Currency != "" && got.Currency != w.Currency {
+			t.Errorf("row %d: currency %q, want %q", i, got.Currency, w.Currency)
+		}
+		if w.Account != "" && got.Account != w.Account {
+			t.Errorf("row %d: account %q, want %q", i, got.Account, w.Account)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package importer parses transaction files from spreadsheets and banks into
+// normalized rows. It does not touch the database: business validation and
+// persistence are left to the expense service.
+package importer
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Supported file formats.
+const (
+	FormatCSV       = "csv"
+	FormatOFX       = "ofx"
+	FormatQIF       = "qif"
+	FormatStatement = "statement" // bank statement download, see ParseStatement
+)
+
+// FormatFromFilename guesses the format from the file extension, defaulting to CSV.
+func FormatFromFilename(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ofx", ".qfx":
+		return FormatOFX
+	case ".qif":
+		return FormatQIF
+	case ".xlsx":
+		return FormatStatement
+	default:
+		return FormatCSV
+	}
+}
+
+// Parse reads r in the given format.
+func Parse(format string, r io.Reader, opts Options) ([]Row, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return ParseCSV(r, opts)
+	case FormatOFX, "qfx":
+		return ParseOFX(r)
+	case FormatQIF:
+		return ParseQIF(r, opts.DateFormat, opts.DecimalSeparator)
+	case FormatStatement:
+		return ParseStatement(r, opts.Bank)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+}
+
+// Fields a column mapping can assign.
+const (
+	FieldDate        = "date"
+	FieldAmount      = "amount"
+	FieldKind        = "kind"
+	FieldType        = "type"
+	FieldCurrency    = "currency"
+	FieldDescription = "description"
+	FieldAccount     = "account"
+	FieldExternalID  = "external_id"
+)
+
+var knownFields = map[string]bool{
+	FieldDate:        true,
+	FieldAmount:      true,
+	FieldKind:        true,
+	FieldType:        true,
+	FieldCurrency:    true,
+	FieldDescription: true,
+	FieldAccount:     true,
+	FieldExternalID:  true,
+}
+
+// Row is one parsed transaction. Date is normalized to YYYY-MM-DD and Amount is
+// signed as in the source file. Kind, Type, Currency and Account are passed
+// through as written (trimmed) and may be empty. ExternalID identifies the
+// transaction in its source (e.g. the OFX FITID) and is used to skip rows that
+// were already imported. Error is set when the row could not be parsed; the
+// other fields are then best effort.
+type Row struct {
+	Line        int
+	Date        string
+	Amount      decimal.Decimal
+	Kind        string
+	Type        string
+	Currency    string
+	Description string
+	Account     string
+	ExternalID  string
+	Error       string
+}
+
+// DateLayout converts a user-facing date pattern such as DD/MM/YYYY into a Go
+// time layout. An empty pattern means YYYY-MM-DD. Day and month accept one or
+// two digits either way.
+func DateLayout(pattern string) (string, error) {
+	if pattern == "" {
+		return "2006-01-02", nil
+	}
+	upper := strings.ToUpper(pattern)
+	if !strings.Contains(upper, "YY") || !strings.Contains(upper, "M") || !strings.Contains(upper, "D") {
+		return "", fmt.Errorf("invalid date format %q, expected something like DD/MM/YYYY", pattern)
+	}
+	return strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "1",
+		"DD", "2",
+		"M", "1",
+		"D", "2",
+	).Replace(upper), nil
+}
+
+// ParseDate parses value with layout and returns it as YYYY-MM-DD.
+func ParseDate(value, layout string) (string, error) {
+	t, err := time.Parse(layout, strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// ParseAmount parses a number written with the given decimal separator ("." or
+// ","). The other separator, spaces and currency symbols are ignored as
+// thousands grouping; a leading minus or surrounding parentheses make it negative.
+func ParseAmount(value, decimalSeparator string) (decimal.Decimal, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return decimal.Zero, errors.New("amount is empty")
+	}
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case string(r) == decimalSeparator:
+			b.WriteByte('.')
+		case r == '-':
+			negative = !negative
+		case r == '+':
+		}
+	}
+	n, err := decimal.NewFromString(b.String())
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		n = n.Neg()
+	}
+	return n, nil
+}
+
+// ParseAmountAuto parses an amount whose separators are not known up front, as
+// in bank statements that mix 1,000,000 and 1.000.000. When both separators
+// appear the last one is the decimal point; a separator that repeats, or is
+// followed by exactly three digits, groups thousands.
+func ParseAmountAuto(value string) (decimal.Decimal, error) {
+	s := strings.TrimSpace(value)
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastDot > lastComma {
+			return ParseAmount(s, ".")
+		}
+		return ParseAmount(s, ",")
+	case lastDot < 0 && lastComma < 0:
+		return ParseAmount(s, ".")
+	}
+	sep, last := ".", lastDot
+	if lastComma >= 0 {
+		sep, last = ",", lastComma
+	}
+	digitsAfter := 0
+	for _, r := range s[last+1:] {
+		if r < '0' || r > '9' {
+			break
+		}
+		digitsAfter++
+	}
+	if strings.Count(s, sep) > 1 || digitsAfter == 3 {
+		// Grouping only: parse with the other separator as decimal point.
+		if sep == "." {
+			return ParseAmount(s, ",")
+		}
+		return ParseAmount(s, ".")
+	}
+	return ParseAmount(s, sep)
+}
+
+// contentID derives a stable external ID for a row that has none in its source,
+// from its contents and how many identical rows came before it in the file.
+func contentID(prefix, key string, seen map[string]int) string {
+	seen[key]++
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+	return prefix + ":" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,235 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Notification is a transaction extracted from a balance-change SMS or push
+// notification. Amount is signed: negative for money out.
+type Notification struct {
+	Bank     string
+	Amount   decimal.Decimal
+	Currency string
+	Date     string // YYYY-MM-DD
+	Time     string // HH:MM[:SS], empty when the message has none
+	Memo     string
+}
+
+// NotificationPattern recognizes one bank's messages. Amount must capture the
+// named groups "amount" and optionally "sign" and "currency"; Memo captures
+// "memo". Direction words decide the sign when the amount has none. Date and
+// time are found with the shared day-first patterns.
+type NotificationPattern struct {
+	Bank   string         // account name the transaction is tagged with
+	Detect *regexp.Regexp // identifies the bank's messages
+	Amount *regexp.Regexp
+	Memo   *regexp.Regexp
+	// Credit and Debit match wording that gives the direction of an unsigned amount.
+	Credit *regexp.Regexp
+	Debit  *regexp.Regexp
+}
+
+var (
+	notificationMu       sync.RWMutex
+	notificationPatterns = map[string]*NotificationPattern{}
+
+	notificationTimeRe = regexp.MustCompile(`\b([01]?\d|2[0-3])[:h]([0-5]\d)(?::([0-5]\d))?\b`)
+	notificationDateRe = regexp.MustCompile(`\b(\d{1,2})[/-](\d{1,2})(?:[/-](\d{2,4}))?\b`)
+
+	genericAmountRe = regexp.MustCompile(`(?i)(?:^|\s)(?P<sign>[+-])\s?(?P<amount>\d[\d.,]*)\s?(?P<currency>VND|USD|EUR|đ)?`)
+	// unsignedAmountRe needs a currency so account numbers are not taken for amounts.
+	unsignedAmountRe = regexp.MustCompile(`(?i)(?:^|\s)(?P<amount>\d[\d.,]*)\s?(?P<currency>VND|USD|EUR|đ)`)
+	genericMemoRe    = regexp.MustCompile(`(?i)(?:\bND|\bNoi dung|\bNội dung|\bND GD|\bRef|\bMo ta|\bMô tả)\s*[:.]?\s*(?P<memo>.+)$`)
+	genericCreditRe  = regexp.MustCompile(`(?i)\b(ghi co|tang|nhan|PS Co|credit)\b`)
+	genericDebitRe   = regexp.MustCompile(`(?i)\b(ghi no|giam|tru|PS No|debit)\b`)
+)
+
+// RegisterNotificationPattern makes p available under code (case-insensitive).
+func RegisterNotificationPattern(code string, p *NotificationPattern) {
+	notificationMu.Lock()
+	defer notificationMu.Unlock()
+	notificationPatterns[strings.ToLower(code)] = p
+}
+
+// NotificationBanks returns the registered bank codes, sorted.
+func NotificationBanks() []string {
+	notificationMu.RLock()
+	defer notificationMu.RUnlock()
+	codes := make([]string, 0, len(notificationPatterns))
+	for code := range notificationPatterns {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func init() {
+	RegisterNotificationPattern("vcb", &NotificationPattern{
+		Bank:   "VCB",
+		Detect: regexp.MustCompile(`(?i)vietcombank|\bVCB\b|^SD TK`),
+		Amount: regexp.MustCompile(`(?i)(?:SD TK|TK)\s*\S+\s*(?:^|\s)(?P<sign>[+-])\s?(?P<amount>\d[\d.,]*)\s?(?P<currency>VND|USD|EUR)?`),
+		Memo:   regexp.MustCompile(`(?i)(?:\bRef|\bND)\s*[:.]?\s*(?P<memo>.+)$`),
+	})
+	RegisterNotificationPattern("bidv", &NotificationPattern{
+		Bank:   "BIDV",
+		Detect: regexp.MustCompile(`(?i)\bBIDV\b`),
+		Amount: regexp.MustCompile(`(?i)So tien GD\s*:?\s*(?P<sign>[+-])?\s?(?P<amount>\d[\d.,]*)\s?(?P<currency>VND|USD|EUR)?`),
+		Memo:   regexp.MustCompile(`(?i)\bND\s*[:.]?\s*(?P<memo>.+)$`),
+	})
+	RegisterNotificationPattern("vpbank", &NotificationPattern{
+		Bank:   "VPBANK",
+		Detect: regexp.MustCompile(`(?i)\bVPBank\b`),
+		Amount: regexp.MustCompile(`(?i)(?:TK\s*\S+\s*\|?\s*)?(?:^|\s)(?P<sign>[+-])\s?(?P<amount>\d[\d.,]*)\s?(?P<currency>VND|USD|EUR)?`),
+		Memo:   regexp.MustCompile(`(?i)\bND\s*[:.]?\s*(?P<memo>.+)$`),
+	})
+	RegisterNotificationPattern("cake", &NotificationPattern{
+		Bank:   "CAKE",
+		Detect: regexp.MustCompile(`(?i)\bCake\b`),
+		Amount: regexp.MustCompile(`(?i)(?:^|\s)(?P<sign>[+-])\s?(?P<amount>\d[\d.,]*)\s?(?P<currency>VND|USD|EUR|đ)?`),
+		Memo:   regexp.MustCompile(`(?i)(?:\bND|\bNoi dung|\bNội dung)\s*[:.]?\s*(?P<memo>.+)$`),
+	})
+}
+
+// genericPattern is used when the message does not identify a known bank.
+var genericPattern = &NotificationPattern{Amount: genericAmountRe, Memo: genericMemoRe}
+
+// ParseNotification extracts a transaction from text. bank selects the pattern
+// explicitly; otherwise it is detected from the text, falling back to generic
+// rules with an empty Bank. Messages without a date are dated today (now).
+func ParseNotification(text, bank string, now time.Time) (*Notification, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil, errors.New("notification text is empty")
+	}
+	pattern, err := notificationPattern(text, bank)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notification{Bank: pattern.Bank}
+	m := findNamed(pattern.Amount, text)
+	if m == nil && pattern != genericPattern {
+		m = findNamed(genericAmountRe, text)
+	}
+	if m == nil {
+		m = findNamed(unsignedAmountRe, text)
+	}
+	if m == nil || m["amount"] == "" {
+		return nil, errors.New("no amount found in the notification")
+	}
+	amount, err := ParseAmountAuto(m["amount"])
+	if err != nil {
+		return nil, err
+	}
+	switch m["sign"] {
+	case "-":
+		amount = amount.Neg()
+	case "+":
+	default:
+		credit, debit := pattern.Credit, pattern.Debit
+		if credit == nil {
+			credit = genericCreditRe
+		}
+		if debit == nil {
+			debit = genericDebitRe
+		}
+		switch {
+		case debit.MatchString(text):
+			amount = amount.Neg()
+		case credit.MatchString(text):
+		default:
+			return nil, errors.New("could not tell whether money came in or went out")
+		}
+	}
+	n.Amount = amount
+	n.Currency = strings.ToUpper(m["currency"])
+	if n.Currency == "Đ" {
+		n.Currency = "VND"
+	}
+
+	memoRe := pattern.Memo
+	if memoRe == nil {
+		memoRe = genericMemoRe
+	}
+	if mm := findNamed(memoRe, text); mm != nil {
+		n.Memo = strings.TrimSpace(mm["memo"])
+	}
+
+	if t := notificationTimeRe.FindString(text); t != "" {
+		n.Time = strings.Replace(t, "h", ":", 1)
+	}
+	n.Date = now.Format("2006-01-02")
+	if d := notificationDateRe.FindStringSubmatch(text); d != nil {
+		date, err := notificationDate(d, now)
+		if err != nil {
+			return nil, err
+		}
+		n.Date = date
+	}
+	return n, nil
+}
+
+func notificationPattern(text, bank string) (*NotificationPattern, error) {
+	notificationMu.RLock()
+	defer notificationMu.RUnlock()
+	if bank != "" {
+		p, ok := notificationPatterns[strings.ToLower(bank)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported bank: %s", bank)
+		}
+		return p, nil
+	}
+	codes := make([]string, 0, len(notificationPatterns))
+	for code := range notificationPatterns {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if p := notificationPatterns[code]; p.Detect != nil && p.Detect.MatchString(text) {
+			return p, nil
+		}
+	}
+	return genericPattern, nil
+}
+
+// notificationDate builds YYYY-MM-DD from a day-first match; a missing year is
+// the current one.
+func notificationDate(m []string, now time.Time) (string, error) {
+	year := now.Year()
+	if m[3] != "" {
+		fmt.Sscanf(m[3], "%d", &year)
+		if len(m[3]) == 2 {
+			year += 2000
+		}
+	}
+	var day, month int
+	fmt.Sscanf(m[1], "%d", &day)
+	fmt.Sscanf(m[2], "%d", &month)
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || int(t.Month()) != month {
+		return "", fmt.Errorf("invalid date %q", m[0])
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+func findNamed(re *regexp.Regexp, text string) map[string]string {
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	out := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			out[name] = m[i]
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNotification(t *testing.T) {
+	now := time.Date(2024, 4, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		bank string
+		want Notification
+	}{
+		{
+			name: "vcb",
+			text: "SD TK 0011000000000 -150,000VND luc 05-03-2024 12:30:45. SD 9,850,000VND. Ref MBVCB.1234567.GRAB 0503",
+			want: Notification{Bank: "VCB", Amount: dec("-150000"), Currency: "VND", Date: "2024-03-05", Time: "12:30:45", Memo: "MBVCB.1234567.GRAB 0503"},
+		},
+		{
+			name: "vcb credit",
+			text: "Vietcombank: SD TK 0011000000000 +15,000,000VND luc 02-03-2024 10:11:12. SD 25,000,000VND. Ref NGUYEN VAN A chuyen tien",
+			want: Notification{Bank: "VCB", Amount: dec("15000000"), Currency: "VND", Date: "2024-03-02", Time: "10:11:12", Memo: "NGUYEN VAN A chuyen tien"},
+		},
+		{
+			name: "bidv",
+			text: "BIDV 10:15 10/03/24 TK 12010000000000 So tien GD:-89,000 So du:4,911,000 ND: SHOPEE PAY 100324",
+			want: Notification{Bank: "BIDV", Amount: dec("-89000"), Date: "2024-03-10", Time: "10:15", Memo: "SHOPEE PAY 100324"},
+		},
+		{
+			name: "vpbank",
+			text: "VPBank: TK 100000000 | -320,000 VND | 05/03/2024 08:30 | SD: 9,680,000 VND | ND: TT HOA DON DIEN EVN",
+			want: Notification{Bank: "VPBANK", Amount: dec("-320000"), Currency: "VND", Date: "2024-03-05", Time: "08:30", Memo: "TT HOA DON DIEN EVN"},
+		},
+		{
+			name: "cake",
+			text: "Cake 16/03/2024 07h00: TK 0000000000 +500.000đ, so du 1.441.000đ. Noi dung: Nhan tien luong",
+			want: Notification{Bank: "CAKE", Amount: dec("500000"), Currency: "VND", Date: "2024-03-16", Time: "07:00", Memo: "Nhan tien luong"},
+		},
+		{
+			name: "explicit bank",
+			text: "TK 100000000 | -20,000 VND | ND: GUI XE",
+			bank: "VPBANK",
+			want: Notification{Bank: "VPBANK", Amount: dec("-20000"), Currency: "VND", Date: "2024-04-20", Memo: "GUI XE"},
+		},
+		{
+			name: "generic with direction word and no year",
+			text: "TK 123456 giam 50,000 VND luc 01/04 ND thanh toan dien thoai",
+			want: Notification{Amount: dec("-50000"), Currency: "VND", Date: "2024-04-01", Memo: "thanh toan dien thoai"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNotification(tt.text, tt.bank, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Bank != tt.want.Bank || !got.Amount.Equal(tt.want.Amount) || got.Currency != tt.want.Currency ||
+				got.Date != tt.want.Date || got.Time != tt.want.Time || got.Memo != tt.want.Memo {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotificationErrors(t *testing.T) {
+	now := time.Date(2024, 4, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name, text, bank, want string
+	}{
+		{"empty", "   ", "", "empty"},
+		{"unknown bank", "TK 1 -1,000 VND", "acb", "unsupported bank"},
+		{"no amount", "Vietcombank: dang nhap thanh cong luc 10:00", "", "no amount"},
+		{"no direction", "So tien 50,000 VND ND abc", "", "came in or went out"},
+		{"invalid date", "BIDV 31/02/2024 So tien GD:-1,000 ND x", "", "invalid date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNotification(tt.text, tt.bank, now)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ofxTransactionRe = regexp.MustCompile(`(?i)<STMTTRN>`)
+	ofxBlockEndRe    = regexp.MustCompile(`(?i)</STMTTRN>|</BANKTRANLIST>|</CCSTMTRS>|</STMTRS>`)
+	ofxCurrencyRe    = regexp.MustCompile(`(?i)<CURDEF>\s*([A-Za-z]{3})`)
+)
+
+// ParseOFX reads an OFX/QFX statement, either the SGML (1.x) or the XML (2.x)
+// flavour. Every STMTTRN becomes a Row: the kind follows the sign of TRNAMT,
+// MEMO (or NAME when there is no memo) becomes the description and FITID is
+// kept as ExternalID. The statement currency (CURDEF) is used for all rows.
+func ParseOFX(r io.Reader) ([]Row, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, errors.New("not an OFX file")
+	}
+	currency := ""
+	if m := ofxCurrencyRe.FindStringSubmatch(content); m != nil {
+		currency = strings.ToUpper(m[1])
+	}
+
+	var rows []Row
+	starts := ofxTransactionRe.FindAllStringIndex(content, -1)
+	for i, loc := range starts {
+		// SGML OFX may omit </STMTTRN>, so a block also ends where the next one starts.
+		block := content[loc[1]:]
+		if i+1 < len(starts) {
+			block = content[loc[1]:starts[i+1][0]]
+		}
+		if end := ofxBlockEndRe.FindStringIndex(block); end != nil {
+			block = block[:end[0]]
+		}
+		row := Row{
+			Line:       i + 1,
+			Currency:   currency,
+			ExternalID: ofxValue(block, "FITID"),
+		}
+		row.Description = ofxValue(block, "MEMO")
+		if row.Description == "" {
+			row.Description = ofxValue(block, "NAME")
+		}
+		var problems []string
+		if row.Date, err = parseOFXDate(ofxValue(block, "DTPOSTED")); err != nil {
+			problems = append(problems, err.Error())
+		}
+		amount := ofxValue(block, "TRNAMT")
+		sep := "."
+		if !strings.Contains(amount, ".") && strings.Contains(amount, ",") {
+			sep = ","
+		}
+		if row.Amount, err = ParseAmount(amount, sep); err != nil {
+			problems = append(problems, err.Error())
+		}
+		row.Kind = kindFromSign(row.Amount)
+		if row.ExternalID == "" {
+			problems = append(problems, "FITID is missing")
+		}
+		row.Error = strings.Join(problems, "; ")
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ofxValue returns the text of the first <tag> in block. SGML OFX leaves
+// elements unclosed, so the value runs until the next tag.
+func ofxValue(block, tag string) string {
+	upper := strings.ToUpper(block)
+	i := strings.Index(upper, "<"+tag+">")
+	if i < 0 {
+		return ""
+	}
+	value := block[i+len(tag)+2:]
+	if j := strings.IndexByte(value, '<'); j >= 0 {
+		value = value[:j]
+	}
+	return decodeSGMLEntities(strings.TrimSpace(value))
+}
+
+func decodeSGMLEntities(s string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&").Replace(s)
+}
+
+// parseOFXDate parses YYYYMMDD[HHMMSS[.XXX]][[+-]gmt:TZ] and keeps the date part.
+func parseOFXDate(value string) (string, error) {
+	if len(value) < 8 {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+func kindFromSign(amount decimal.Decimal) string {
+	if amount.IsNegative() {
+		return "expense"
+	}
+	return "income"
+}
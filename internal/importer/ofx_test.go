@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		file     string
+		currency string
+		want     []Row
+	}{
+		{
+			file:     "testdata/statement.ofx",
+			currency: "USD",
+			want: []Row{
+				{Line: 1, Date: "2024-03-05", Amount: dec("-42.5"), Kind: "expense", Description: "COFFEE SHOP", ExternalID: "T0001"},
+				{Line: 2, Date: "2024-03-10", Amount: dec("1500"), Kind: "income", Description: "Salary & bonus", ExternalID: "T0002"},
+				{Line: 3}, // short date and no FITID
+			},
+		},
+		{
+			file:     "testdata/statement.qfx",
+			currency: "EUR",
+			want: []Row{
+				{Line: 1, Date: "2024-01-02", Amount: dec("-9.99"), Kind: "expense", Description: "STREAMING", ExternalID: "CC-1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			rows, err := ParseOFX(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareRows(t, rows, tt.want)
+			for _, row := range rows {
+				if row.Currency != tt.currency {
+					t.Errorf("line %d: currency %q, want %q", row.Line, row.Currency, tt.currency)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOFXRejectsOtherFiles(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("date,amount\n2024-01-01,1\n")); err == nil {
+		t.Error("expected an error for a CSV file")
+	}
+}
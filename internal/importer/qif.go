@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// qifTransactionSections are the headers, lower-cased, of the sections that
+// hold transactions.
+var qifTransactionSections = map[string]bool{
+	"!type:bank":  true,
+	"!type:cash":  true,
+	"!type:ccard": true,
+	"!type:oth a": true,
+	"!type:oth l": true,
+	"!type:invst": true,
+}
+
+// ParseQIF reads a QIF file. Each record (terminated by ^) becomes a Row: D is
+// the date in dateFormat (default MM/DD/YYYY, two-digit and Quicken 'YY years
+// are accepted), T or U the amount, M the memo (falling back to the payee P)
+// and L the category, used as the type. The kind follows the sign of the
+// amount. Only records of transaction sections (!Type:Bank, Cash, CCard,
+// Oth A, Oth L and Invst) become rows; account lists, categories, classes and
+// other sections are skipped. QIF has no transaction IDs, so ExternalID is derived from the record
+// contents and its position among identical records of the file.
+func ParseQIF(r io.Reader, dateFormat, decimalSeparator string) ([]Row, error) {
+	if dateFormat == "" {
+		dateFormat = "MM/DD/YYYY"
+	}
+	layout, err := DateLayout(dateFormat)
+	if err != nil {
+		return nil, err
+	}
+	shortLayout := strings.Replace(layout, "2006", "06", 1)
+	if decimalSeparator == "" {
+		decimalSeparator = "."
+	}
+
+	var rows []Row
+	seen := map[string]int{}
+	var rec map[byte]string
+	start := 0
+	// Records before the first header are read as transactions.
+	inTransactions := true
+	flush := func() {
+		if len(rec) == 0 {
+			return
+		}
+		if !inTransactions {
+			rec = nil
+			return
+		}
+		row := Row{Line: start}
+		var problems []string
+		date := strings.ReplaceAll(strings.ReplaceAll(rec['D'], "'", "/"), " ", "")
+		if row.Date, err = ParseDate(date, layout); err != nil {
+			if row.Date, err = ParseDate(date, shortLayout); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+		amount := rec['T']
+		if amount == "" {
+			amount = rec['U']
+		}
+		if row.Amount, err = ParseAmount(amount, decimalSeparator); err != nil {
+			problems = append(problems, err.Error())
+		}
+		row.Kind = kindFromSign(row.Amount)
+		row.Description = rec['M']
+		if row.Description == "" {
+			row.Description = rec['P']
+		}
+		if category := rec['L']; !strings.HasPrefix(category, "[") {
+			row.Type = category
+		}
+		row.Error = strings.Join(problems, "; ")
+		if row.Error == "" {
+			key := fmt.Sprintf("%s|%s|%s|%s|%s", row.Date, row.Amount.StringFixed(2), rec['P'], rec['M'], rec['N'])
+			row.ExternalID = contentID("qif", key, seen)
+		}
+		rows = append(rows, row)
+		rec = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	sawHeader := false
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		switch code := text[0]; code {
+		case '!':
+			sawHeader = true
+			flush()
+			inTransactions = qifTransactionSections[strings.ToLower(strings.TrimSpace(text))]
+		case '^':
+			flush()
+		case 'S', 'E', '$', '%':
+			// Split lines are not imported separately.
+		default:
+			if rec == nil {
+				rec = map[byte]string{}
+				start = line
+			}
+			if _, ok := rec[code]; !ok {
+				rec[code] = strings.TrimSpace(text[1:])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	if !sawHeader && len(rows) == 0 {
+		return nil, errors.New("not a QIF file")
+	}
+	return rows, nil
+}
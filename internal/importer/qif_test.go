@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQIF(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		dateFormat string
+		decimalSep string
+		want       []Row // Line, Date, Amount, Kind, Type and Description are compared
+		wantErr    bool
+	}{
+		{
+			name: "bank section",
+			input: "!Type:Bank\n" +
+				"D03/15/2024\nT-150,000.00\nPGrab\nLFood\n^\n" +
+				"D3/16'24\nU2000000\nPEmployer\nMSalary\nL[Savings]\n^\n",
+			want: []Row{
+				{Line: 2, Date: "2024-03-15", Amount: dec("-150000"), Kind: "expense", Type: "Food", Description: "Grab"},
+				{Line: 7, Date: "2024-03-16", Amount: dec("2000000"), Kind: "income", Description: "Salary"},
+			},
+		},
+		{
+			name: "multi-account export skips non-transaction sections",
+			input: "!Option:AutoSwitch\n" +
+				"!Account\nNChecking\nTBank\n^\nNWallet\nTCash\n^\n" +
+				"!Clear:AutoSwitch\n" +
+				"!Type:Cat\nNFood\nE\n^\n" +
+				"!Type:Class\nNWork\n^\n" +
+				"!Account\nNChecking\nTBank\n^\n" +
+				"!Type:Bank\nD01/02/2024\nT-50.5\nPCafe\n^\n" +
+				"!Account\nNWallet\nTCash\n^\n" +
+				"!Type:Cash \nD01/03/2024\nT-20\nMParking\n^\n",
+			want: []Row{
+				{Line: 22, Date: "2024-01-02", Amount: dec("-50.5"), Kind: "expense", Description: "Cafe"},
+				{Line: 31, Date: "2024-01-03", Amount: dec("-20"), Kind: "expense", Description: "Parking"},
+			},
+		},
+		{
+			name:       "day-first dates and comma decimals",
+			input:      "!Type:CCard\r\nD15/03/2024\r\nT-1.234,50\r\nMBook\r\n^\r\n",
+			dateFormat: "DD/MM/YYYY",
+			decimalSep: ",",
+			want: []Row{
+				{Line: 2, Date: "2024-03-15", Amount: dec("-1234.5"), Kind: "expense", Description: "Book"},
+			},
+		},
+		{
+			name:  "invalid record is reported",
+			input: "!Type:Bank\nDnot a date\nTabc\n^\n",
+			want:  []Row{{Line: 2, Kind: "income"}},
+		},
+		{
+			name:    "not a QIF file",
+			input:   "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseQIF(strings.NewReader(tt.input), tt.dateFormat, tt.decimalSep)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d rows", len(rows))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestParseQIFExternalIDs(t *testing.T) {
+	input := "!Type:Bank\nD01/02/2024\nT-10\nPCafe\n^\nD01/02/2024\nT-10\nPCafe\n^\n"
+	first, err := ParseQIF(strings.NewReader(input), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseQIF(strings.NewReader(input), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first[0].ExternalID == "" || first[0].ExternalID == first[1].ExternalID {
+		t.Errorf("identical records need distinct IDs, got %q and %q", first[0].ExternalID, first[1].ExternalID)
+	}
+	if first[0].ExternalID != again[0].ExternalID || first[1].ExternalID != again[1].ExternalID {
+		t.Error("IDs change between imports of the same file")
+	}
+}
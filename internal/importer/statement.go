@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// StatementParser reads the transaction table of one bank's statement download.
+// Statements usually start with a preamble (bank name, account number, period)
+// followed by a header row and the transactions, and end with totals.
+type StatementParser interface {
+	// Bank is the account name the transactions are tagged with, e.g. "VCB".
+	Bank() string
+	// Detect reports whether preamble (the rows above a candidate header, joined
+	// and normalized with NormalizeText) identifies this bank. It is only used
+	// when the bank is not given explicitly.
+	Detect(preamble string) bool
+	// MatchHeader reports whether row is this bank's transaction header.
+	MatchHeader(header []string) bool
+	// ParseRows converts the rows below the header into Rows. line is the
+	// 1-based position of the first row in the file.
+	ParseRows(header []string, rows [][]string, line int) []Row
+}
+
+var (
+	statementMu      sync.RWMutex
+	statementParsers = map[string]StatementParser{}
+)
+
+// RegisterStatementParser makes p available under code (case-insensitive).
+// Registering the same code again replaces the previous parser.
+func RegisterStatementParser(code string, p StatementParser) {
+	statementMu.Lock()
+	defer statementMu.Unlock()
+	statementParsers[strings.ToLower(code)] = p
+}
+
+// StatementBanks returns the registered bank codes, sorted.
+func StatementBanks() []string {
+	statementMu.RLock()
+	defer statementMu.RUnlock()
+	codes := make([]string, 0, len(statementParsers))
+	for code := range statementParsers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// maxHeaderSearch is how many leading rows are searched for the header.
+const maxHeaderSearch = 40
+
+// ParseStatement reads a bank statement exported as CSV or XLSX (first sheet).
+// When bank is empty the parser is chosen by the statement preamble, or by the
+// header alone when the file starts with it. The rows are tagged with the
+// bank's account name.
+func ParseStatement(r io.Reader, bank string) ([]Row, error) {
+	table, err := readTable(r)
+	if err != nil {
+		return nil, err
+	}
+
+	statementMu.RLock()
+	var candidates []StatementParser
+	if bank != "" {
+		p, ok := statementParsers[strings.ToLower(bank)]
+		if !ok {
+			statementMu.RUnlock()
+			return nil, fmt.Errorf("unsupported bank: %s", bank)
+		}
+		candidates = []StatementParser{p}
+	} else {
+		for _, code := range sortedKeys(statementParsers) {
+			candidates = append(candidates, statementParsers[code])
+		}
+	}
+	statementMu.RUnlock()
+
+	var preamble strings.Builder
+	for i := 0; i < len(table) && i < maxHeaderSearch; i++ {
+		var matched []StatementParser
+		for _, p := range candidates {
+			if bank == "" && preamble.Len() > 0 && !p.Detect(preamble.String()) {
+				continue
+			}
+			if p.MatchHeader(table[i]) {
+				matched = append(matched, p)
+			}
+		}
+		// Without a preamble (the header is the first row) only the header
+		// identifies the bank, so it must fit a single layout.
+		if len(matched) == 1 || (len(matched) > 1 && preamble.Len() > 0) {
+			p := matched[0]
+			rows := p.ParseRows(table[i], table[i+1:], i+2)
+			for j := range rows {
+				rows[j].Account = p.Bank()
+			}
+			return rows, nil
+		}
+		if len(matched) > 1 {
+			break
+		}
+		preamble.WriteString(NormalizeText(strings.Join(table[i], " ")))
+		preamble.WriteByte(' ')
+	}
+	if bank != "" {
+		return nil, fmt.Errorf("no %s transaction header found in the statement", strings.ToUpper(bank))
+	}
+	return nil, errors.New("could not recognize the bank statement, pass the bank explicitly")
+}
+
+func sortedKeys(m map[string]StatementParser) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readTable loads an XLSX (detected by its zip signature) or CSV file as rows of cells.
+func readTable(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open workbook: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+		return f.GetRows(sheets[0])
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	sample := data
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	reader := csv.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	reader.Comma = detectDelimiter(string(sample))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader.ReadAll()
+}
+
+var diacritics = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// NormalizeText lower-cases s, strips Vietnamese diacritics and collapses
+// whitespace, so "Số tiền ghi nợ" and "so tien  ghi no" compare equal.
+func NormalizeText(s string) string {
+	s = strings.NewReplacer("đ", "d", "Đ", "d").Replace(s)
+	if out, _, err := transform.String(diacritics, s); err == nil {
+		s = out
+	}
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
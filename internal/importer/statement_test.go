@@ -0,0 +1,276 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// statementFixtures are anonymized statement exports in testdata, with the
+// line of their header row.
+var statementFixtures = []struct {
+	file    string
+	bank    string
+	account string
+	header  int
+	want    []Row
+}{
+	{
+		file: "vcb.csv", bank: "vcb", account: "VCB", header: 6,
+		want: []Row{
+			{Line: 7, Date: "2024-03-01", Amount: dec("-150000"), Kind: "expense", Description: "THANH TOAN GRAB 0301", ExternalID: "5213.10001"},
+			{Line: 8, Date: "2024-03-02", Amount: dec("15000000"), Kind: "income", Description: "NGUYEN VAN A chuyen tien luong"},
+			{Line: 10, Date: "2024-03-02", Amount: dec("-45500"), Kind: "expense", Description: "PHI SMS BANKING"},
+			{Line: 11, Date: "2024-03-02", Amount: dec("-45500"), Kind: "expense", Description: "PHI SMS BANKING"},
+			{Line: 12}, // 31/02 does not exist
+		},
+	},
+	{
+		file: "vpbank.csv", bank: "vpbank", account: "VPBANK", header: 4,
+		want: []Row{
+			{Line: 5, Date: "2024-03-05", Amount: dec("-320000"), Kind: "expense", Description: "TT HOA DON DIEN EVN", ExternalID: "FT24065000001"},
+			{Line: 6, Date: "2024-03-06", Amount: dec("1500000"), Kind: "income", Description: "NHAN TIEN TU TRAN THI B", ExternalID: "FT24066000002"},
+			{Line: 8, Date: "2024-03-07", Amount: dec("-2000000"), Kind: "expense", Description: "RUT TIEN ATM", ExternalID: "FT24067000003"},
+		},
+	},
+	{
+		file: "bidv.csv", bank: "bidv", account: "BIDV", header: 4,
+		want: []Row{
+			{Line: 5, Date: "2024-03-10", Amount: dec("-89000"), Kind: "expense", Description: "SHOPEE PAY 100324", ExternalID: "BIDV0001"},
+			{Line: 6, Date: "2024-03-11", Amount: dec("250000.5"), Kind: "income", Description: "HOAN TIEN", ExternalID: "BIDV0002"},
+		},
+	},
+	{
+		file: "cake.csv", bank: "cake", account: "CAKE", header: 3,
+		want: []Row{
+			{Line: 4, Date: "2024-03-15", Amount: dec("-59000"), Kind: "expense", Description: "Highlands Coffee", ExternalID: "CK001"},
+			{Line: 5, Date: "2024-03-16", Amount: dec("500000"), Kind: "income", Description: "Nhan tien", ExternalID: "CK002"},
+		},
+	},
+	{
+		file: "cake_signed.csv", bank: "cake", account: "CAKE", header: 1,
+		want: []Row{
+			{Line: 2, Date: "2024-03-20", Amount: dec("-120000"), Kind: "expense", Description: "Grab Food"},
+			{Line: 3, Date: "2024-03-21", Amount: dec("20000"), Kind: "income", Description: "Hoan tien"},
+		},
+	},
+}
+
+func TestParseStatement(t *testing.T) {
+	for _, tt := range statementFixtures {
+		data, err := os.ReadFile("testdata/" + tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records := readCSVFixture(t, data)
+		inputs := []struct {
+			name string
+			data []byte
+		}{
+			{"csv", data},
+			{"xlsx", xlsxFixture(t, records)},
+		}
+		for _, in := range inputs {
+			for _, bank := range []string{"", tt.bank, strings.ToUpper(tt.bank)} {
+				t.Run(tt.file+"/"+in.name+"/bank="+bank, func(t *testing.T) {
+					rows, err := ParseStatement(bytes.NewReader(in.data), bank)
+					if err != nil {
+						t.Fatal(err)
+					}
+					compareRows(t, rows, tt.want)
+					checkStatementRows(t, rows, tt.account)
+				})
+			}
+		}
+	}
+}
+
+// TestParseStatementHeaderFirst parses the fixtures without their preamble, so
+// only the header can identify the bank.
+func TestParseStatementHeaderFirst(t *testing.T) {
+	for _, tt := range statementFixtures {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			records := readCSVFixture(t, data)[tt.header-1:]
+			rows, err := ParseStatement(bytes.NewReader(xlsxFixture(t, records)), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]Row, len(tt.want))
+			for i, w := range tt.want {
+				w.Line -= tt.header - 1
+				want[i] = w
+			}
+			compareRows(t, rows, want)
+			checkStatementRows(t, rows, tt.account)
+		})
+	}
+}
+
+func TestParseStatementErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		bank  string
+		want  string
+	}{
+		{
+			name:  "unknown bank",
+			input: "Ngày giao dịch,Mô tả,Số tiền\n",
+			bank:  "acb",
+			want:  "unsupported bank: acb",
+		},
+		{
+			name:  "no header of the given bank",
+			input: "Vietcombank\nDate,Description,Amount\n01/03/2024,x,1\n",
+			bank:  "vcb",
+			want:  "no VCB transaction header found",
+		},
+		{
+			name:  "preamble of no known bank",
+			input: "ACME Bank\nNgày giao dịch,Mã giao dịch,Mô tả,Tiền vào,Tiền ra\n",
+			want:  "could not recognize",
+		},
+		{
+			// Without a preamble this header fits both VCB and VPBank.
+			name:  "ambiguous header",
+			input: "Ngày giao dịch,Ghi nợ,Ghi có,Mô tả,Nội dung\n01/03/2024,1,,x,x\n",
+			want:  "could not recognize",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatement(strings.NewReader(tt.input), tt.bank)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseStatementTypedCells reads an XLSX whose dates and amounts are typed
+// cells rather than text.
+func TestParseStatementTypedCells(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	dateFormat := "dd/mm/yyyy"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetSheetRow(sheet, "A1", &[]interface{}{"Vietcombank"})
+	f.SetSheetRow(sheet, "A2", &[]interface{}{"STT", "Ngày giao dịch", "Số tham chiếu", "Số tiền ghi nợ", "Số tiền ghi có", "Mô tả"})
+	f.SetSheetRow(sheet, "A3", &[]interface{}{1, time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), "R1", 75000, nil, "Cafe"})
+	f.SetCellStyle(sheet, "B3", "B3", dateStyle)
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ParseStatement(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareRows(t, rows, []Row{
+		{Line: 3, Date: "2024-03-09", Amount: dec("-75000"), Kind: "expense", Description: "Cafe", ExternalID: "R1", Account: "VCB"},
+	})
+}
+
+func TestParseAmountAuto(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "150000", want: "150000"},
+		{in: "150,000", want: "150000"},
+		{in: "150.000", want: "150000"},
+		{in: "15,000,000", want: "15000000"},
+		{in: "15.000.000", want: "15000000"},
+		{in: "1,234.56", want: "1234.56"},
+		{in: "1.234,56", want: "1234.56"},
+		{in: "250,000.50", want: "250000.5"},
+		{in: "12.5", want: "12.5"},
+		{in: "12,5", want: "12.5"},
+		{in: "-120,000", want: "-120000"},
+		{in: "+20,000", want: "20000"},
+		{in: "(1,000)", want: "-1000"},
+		{in: "1,000 VND", want: "1000"},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmountAuto(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmountAuto(%q) = %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(dec(tt.want)) {
+			t.Errorf("ParseAmountAuto(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// checkStatementRows checks what every statement row has in common: the
+// bank's account and an external ID, unique within the file.
+func checkStatementRows(t *testing.T, rows []Row, account string) {
+	t.Helper()
+	ids := map[string]bool{}
+	for _, row := range rows {
+		if row.Account != account {
+			t.Errorf("line %d: account %q, want %q", row.Line, row.Account, account)
+		}
+		if row.Error != "" {
+			continue
+		}
+		if row.ExternalID == "" || ids[row.ExternalID] {
+			t.Errorf("line %d: external ID %q is empty or repeated", row.Line, row.ExternalID)
+		}
+		ids[row.ExternalID] = true
+	}
+}
+
+func readCSVFixture(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+// xlsxFixture writes records as text cells of a workbook's first sheet.
+func xlsxFixture(t *testing.T, records [][]string) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	for i, record := range records {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		values := make([]interface{}, len(record))
+		for j, v := range record {
+			values[j] = v
+		}
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,218 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCurrency is returned for a code that is not a known currency.
+var ErrInvalidCurrency = errors.New("invalid currency")
+
+// Currency describes an ISO 4217 currency.
+type Currency struct {
+	Code       string
+	Name       string
+	Symbol     string // empty for funds and units of account
+	MinorUnits int32
+}
+
+// currencies is the ISO 4217 list of active codes, including funds, sorted by
+// code. Precious metals and other codes without a minor unit (XAU, XDR, ...)
+// are left out.
+var currencies = []Currency{
+	{"AED", "UAE Dirham", "د.إ", 2},
+	{"AFN", "Afghani", "؋", 2},
+	{"ALL", "Lek", "L", 2},
+	{"AMD", "Armenian Dram", "֏", 2},
+	{"ANG", "Netherlands Antillean Guilder", "ƒ", 2},
+	{"AOA", "Kwanza", "Kz", 2},
+	{"ARS", "Argentine Peso", "$", 2},
+	{"AUD", "Australian Dollar", "A$", 2},
+	{"AWG", "Aruban Florin", "ƒ", 2},
+	{"AZN", "Azerbaijan Manat", "₼", 2},
+	{"BAM", "Convertible Mark", "KM", 2},
+	{"BBD", "Barbados Dollar", "Bds$", 2},
+	{"BDT", "Taka", "৳", 2},
+	{"BGN", "Bulgarian Lev", "лв", 2},
+	{"BHD", "Bahraini Dinar", ".د.ب", 3},
+	{"BIF", "Burundi Franc", "FBu", 0},
+	{"BMD", "Bermudian Dollar", "$", 2},
+	{"BND", "Brunei Dollar", "B$", 2},
+	{"BOB", "Boliviano", "Bs", 2},
+	{"BOV", "Mvdol", "", 2},
+	{"BRL", "Brazilian Real", "R$", 2},
+	{"BSD", "Bahamian Dollar", "$", 2},
+	{"BTN", "Ngultrum", "Nu.", 2},
+	{"BWP", "Pula", "P", 2},
+	{"BYN", "Belarusian Ruble", "Br", 2},
+	{"BZD", "Belize Dollar", "BZ$", 2},
+	{"CAD", "Canadian Dollar", "CA$", 2},
+	{"CDF", "Congolese Franc", "FC", 2},
+	{"CHE", "WIR Euro", "", 2},
+	{"CHF", "Swiss Franc", "CHF", 2},
+	{"CHW", "WIR Franc", "", 2},
+	{"CLF", "Unidad de Fomento", "", 4},
+	{"CLP", "Chilean Peso", "$", 0},
+	{"CNY", "Yuan Renminbi", "¥", 2},
+	{"COP", "Colombian Peso", "$", 2},
+	{"COU", "Unidad de Valor Real", "", 2},
+	{"CRC", "Costa Rican Colon", "₡", 2},
+	{"CUP", "Cuban Peso", "$", 2},
+	{"CVE", "Cabo Verde Escudo", "Esc", 2},
+	{"CZK", "Czech Koruna", "Kč", 2},
+	{"DJF", "Djibouti Franc", "Fdj", 0},
+	{"DKK", "Danish Krone", "kr", 2},
+	{"DOP", "Dominican Peso", "RD$", 2},
+	{"DZD", "Algerian Dinar", "د.ج", 2},
+	{"EGP", "Egyptian Pound", "E£", 2},
+	{"ERN", "Nakfa", "Nfk", 2},
+	{"ETB", "Ethiopian Birr", "Br", 2},
+	{"EUR", "Euro", "€", 2},
+	{"FJD", "Fiji Dollar", "FJ$", 2},
+	{"FKP", "Falkland Islands Pound", "£", 2},
+	{"GBP", "Pound Sterling", "£", 2},
+	{"GEL", "Lari", "₾", 2},
+	{"GHS", "Ghana Cedi", "GH₵", 2},
+	{"GIP", "Gibraltar Pound", "£", 2},
+	{"GMD", "Dalasi", "D", 2},
+	{"GNF", "Guinean Franc", "FG", 0},
+	{"GTQ", "Quetzal", "Q", 2},
+	{"GYD", "Guyana Dollar", "G$", 2},
+	{"HKD", "Hong Kong Dollar", "HK$", 2},
+	{"HNL", "Lempira", "L", 2},
+	{"HTG", "Gourde", "G", 2},
+	{"HUF", "Forint", "Ft", 2},
+	{"IDR", "Rupiah", "Rp", 2},
+	{"ILS", "New Israeli Sheqel", "₪", 2},
+	{"INR", "Indian Rupee", "₹", 2},
+	{"IQD", "Iraqi Dinar", "ع.د", 3},
+	{"IRR", "Iranian Rial", "﷼", 2},
+	{"ISK", "Iceland Krona", "kr", 0},
+	{"JMD", "Jamaican Dollar", "J$", 2},
+	{"JOD", "Jordanian Dinar", "JD", 3},
+	{"JPY", "Yen", "¥", 0},
+	{"KES", "Kenyan Shilling", "KSh", 2},
+	{"KGS", "Som", "сом", 2},
+	{"KHR", "Riel", "៛", 2},
+	{"KMF", "Comorian Franc", "CF", 0},
+	{"KPW", "North Korean Won", "₩", 2},
+	{"KRW", "Won", "₩", 0},
+	{"KWD", "Kuwaiti Dinar", "KD", 3},
+	{"KYD", "Cayman Islands Dollar", "CI$", 2},
+	{"KZT", "Tenge", "₸", 2},
+	{"LAK", "Lao Kip", "₭", 2},
+	{"LBP", "Lebanese Pound", "ل.ل", 2},
+	{"LKR", "Sri Lanka Rupee", "Rs", 2},
+	{"LRD", "Liberian Dollar", "L$", 2},
+	{"LSL", "Loti", "L", 2},
+	{"LYD", "Libyan Dinar", "LD", 3},
+	{"MAD", "Moroccan Dirham", "DH", 2},
+	{"MDL", "Moldovan Leu", "L", 2},
+	{"MGA", "Malagasy Ariary", "Ar", 2},
+	{"MKD", "Denar", "ден", 2},
+	{"MMK", "Kyat", "K", 2},
+	{"MNT", "Tugrik", "₮", 2},
+	{"MOP", "Pataca", "MOP$", 2},
+	{"MRU", "Ouguiya", "UM", 2},
+	{"MUR", "Mauritius Rupee", "₨", 2},
+	{"MVR", "Rufiyaa", "Rf", 2},
+	{"MWK", "Malawi Kwacha", "MK", 2},
+	{"MXN", "Mexican Peso", "MX$", 2},
+	{"MXV", "Mexican Unidad de Inversion (UDI)", "", 2},
+	{"MYR", "Malaysian Ringgit", "RM", 2},
+	{"MZN", "Mozambique Metical", "MT", 2},
+	{"NAD", "Namibia Dollar", "N$", 2},
+	{"NGN", "Naira", "₦", 2},
+	{"NIO", "Cordoba Oro", "C$", 2},
+	{"NOK", "Norwegian Krone", "kr", 2},
+	{"NPR", "Nepalese Rupee", "Rs", 2},
+	{"NZD", "New Zealand Dollar", "NZ$", 2},
+	{"OMR", "Rial Omani", "ر.ع.", 3},
+	{"PAB", "Balboa", "B/.", 2},
+	{"PEN", "Sol", "S/", 2},
+	{"PGK", "Kina", "K", 2},
+	{"PHP", "Philippine Peso", "₱", 2},
+	{"PKR", "Pakistan Rupee", "Rs", 2},
+	{"PLN", "Zloty", "zł", 2},
+	{"PYG", "Guarani", "₲", 0},
+	{"QAR", "Qatari Rial", "ر.ق", 2},
+	{"RON", "Romanian Leu", "lei", 2},
+	{"RSD", "Serbian Dinar", "дин.", 2},
+	{"RUB", "Russian Ruble", "₽", 2},
+	{"RWF", "Rwanda Franc", "FRw", 0},
+	{"SAR", "Saudi Riyal", "ر.س", 2},
+	{"SBD", "Solomon Islands Dollar", "SI$", 2},
+	{"SCR", "Seychelles Rupee", "₨", 2},
+	{"SDG", "Sudanese Pound", "ج.س.", 2},
+	{"SEK", "Swedish Krona", "kr", 2},
+	{"SGD", "Singapore Dollar", "S$", 2},
+	{"SHP", "Saint Helena Pound", "£", 2},
+	{"SLE", "Leone", "Le", 2},
+	{"SOS", "Somali Shilling", "Sh", 2},
+	{"SRD", "Surinam Dollar", "$", 2},
+	{"SSP", "South Sudanese Pound", "£", 2},
+	{"STN", "Dobra", "Db", 2},
+	{"SVC", "El Salvador Colon", "₡", 2},
+	{"SYP", "Syrian Pound", "£S", 2},
+	{"SZL", "Lilangeni", "E", 2},
+	{"THB", "Baht", "฿", 2},
+	{"TJS", "Somoni", "SM", 2},
+	{"TMT", "Turkmenistan New Manat", "m", 2},
+	{"TND", "Tunisian Dinar", "DT", 3},
+	{"TOP", "Pa'anga", "T$", 2},
+	{"TRY", "Turkish Lira", "₺", 2},
+	{"TTD", "Trinidad and Tobago Dollar", "TT$", 2},
+	{"TWD", "New Taiwan Dollar", "NT$", 2},
+	{"TZS", "Tanzanian Shilling", "TSh", 2},
+	{"UAH", "Hryvnia", "₴", 2},
+	{"UGX", "Uganda Shilling", "USh", 0},
+	{"USD", "US Dollar", "$", 2},
+	{"USN", "US Dollar (Next day)", "", 2},
+	{"UYI", "Uruguay Peso en Unidades Indexadas (UI)", "", 0},
+	{"UYU", "Peso Uruguayo", "$U", 2},
+	{"UYW", "Unidad Previsional", "", 4},
+	{"UZS", "Uzbekistan Sum", "soʻm", 2},
+	{"VED", "Bolívar Soberano", "Bs.D", 2},
+	{"VES", "Bolívar Soberano", "Bs.S", 2},
+	{"VND", "Dong", "₫", 0},
+	{"VUV", "Vatu", "VT", 0},
+	{"WST", "Tala", "WS$", 2},
+	{"XAF", "CFA Franc BEAC", "FCFA", 0},
+	{"XCD", "East Caribbean Dollar", "EC$", 2},
+	{"XCG", "Caribbean Guilder", "Cg", 2},
+	{"XOF", "CFA Franc BCEAO", "CFA", 0},
+	{"XPF", "CFP Franc", "₣", 0},
+	{"YER", "Yemeni Rial", "﷼", 2},
+	{"ZAR", "Rand", "R", 2},
+	{"ZMW", "Zambian Kwacha", "ZK", 2},
+	{"ZWG", "Zimbabwe Gold", "ZiG", 2},
+}
+
+var currencyByCode = func() map[string]Currency {
+	m := make(map[string]Currency, len(currencies))
+	for _, c := range currencies {
+		m[c.Code] = c
+	}
+	return m
+}()
+
+// Currencies returns every known currency, sorted by code.
+func Currencies() []Currency {
+	return append([]Currency(nil), currencies...)
+}
+
+// Lookup returns the currency with the given code, in any case.
+func Lookup(code string) (Currency, bool) {
+	c, ok := currencyByCode[strings.ToUpper(code)]
+	return c, ok
+}
+
+// ValidateCurrency reports an error unless code is a known upper-case ISO 4217
+// code.
+func ValidateCurrency(code string) error {
+	if _, ok := currencyByCode[code]; !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidCurrency, code)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package money holds the rules for currency amounts. Amounts are exact
+// decimals stored with the precision of their currency's minor unit
+// (0 decimals for VND, 2 for USD, ...) and only rounded explicitly.
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Amounts are encoded as JSON numbers, as they were before they became
+// decimals. The models import this package, so the setting is in place
+// wherever amounts are serialized.
+func init() {
+	decimal.MarshalJSONWithoutQuotes = true
+}
+
+// DefaultMinorUnits is used for currencies missing from the ISO 4217 table.
+const DefaultMinorUnits = 2
+
+// StorageScale is the number of decimals of the NUMERIC amount columns. It
+// covers the minor unit of every known currency.
+const StorageScale = 4
+
+// MinorUnits returns the number of decimals of the currency's minor unit.
+func MinorUnits(currency string) int32 {
+	if c, ok := Lookup(currency); ok {
+		return c.MinorUnits
+	}
+	return DefaultMinorUnits
+}
+
+// Round rounds amount to the minor unit of currency, half away from zero.
+func Round(amount decimal.Decimal, currency string) decimal.Decimal {
+	return amount.Round(MinorUnits(currency))
+}
+
+// Validate reports an error when amount has more decimals than the minor
+// unit of currency allows.
+func Validate(amount decimal.Decimal, currency string) error {
+	if !amount.Equal(Round(amount, currency)) {
+		return fmt.Errorf("amount %s has more than %d decimals for %s", amount, MinorUnits(currency), strings.ToUpper(currency))
+	}
+	return nil
+}
+
+// Convert expresses amount in the to currency, given the value of one unit of
+// each currency in a common base (fromRate and toRate). The result is rounded
+// to the minor unit of to. A zero rate counts as 1.
+func Convert(amount decimal.Decimal, fromRate, toRate decimal.Decimal, to string) decimal.Decimal {
+	if fromRate.IsZero() {
+		fromRate = decimal.NewFromInt(1)
+	}
+	if toRate.IsZero() {
+		toRate = decimal.NewFromInt(1)
+	}
+	return Round(amount.Mul(fromRate).Div(toRate), to)
+}
+
+// ScaleSQL returns a SQL expression giving the minor units of the currency
+// stored in column, for rounding amounts in the database.
+func ScaleSQL(column string) string {
+	byUnits := make(map[int32][]string)
+	for _, c := range currencies {
+		if c.MinorUnits != DefaultMinorUnits {
+			byUnits[c.MinorUnits] = append(byUnits[c.MinorUnits], "'"+c.Code+"'")
+		}
+	}
+	keys := make([]int, 0, len(byUnits))
+	for units := range byUnits {
+		keys = append(keys, int(units))
+	}
+	sort.Ints(keys)
+
+	var b strings.Builder
+	b.WriteString("CASE")
+	for _, units := range keys {
+		codes := byUnits[int32(units)]
+		fmt.Fprintf(&b, " WHEN UPPER(%s) IN (%s) THEN %d", column, strings.Join(codes, ", "), units)
+	}
+	fmt.Fprintf(&b, " ELSE %d END", DefaultMinorUnits)
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package recurring
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Generator periodically materializes due recurring occurrences into expenses.
+type Generator struct {
+	Service  *RecurringService
+	Interval time.Duration
+}
+
+func NewGenerator(service *RecurringService, interval time.Duration) *Generator {
+	return &Generator{Service: service, Interval: interval}
+}
+
+// Start runs the generator once immediately and then on every tick, in the background.
+func (g *Generator) Start() {
+	go func() {
+		g.run()
+		ticker := time.NewTicker(g.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			g.run()
+		}
+	}()
+}
+
+func (g *Generator) run() {
+	today := time.Now().Format(dateLayout)
+	created, err := g.Service.GenerateDue(today)
+	if err != nil {
+		slog.Error("recurring: generator run failed", "error", err)
+		return
+	}
+	if created > 0 {
+		slog.Info("recurring: generated occurrences", "count", created, "date", today)
+	}
+}
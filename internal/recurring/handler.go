@@ -0,0 +1,274 @@
+package recurring
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"mindoh-service/common/utils"
+	"mindoh-service/internal/auth"
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecurringHandler handles HTTP requests for recurring rules
+type RecurringHandler struct {
+	Service *RecurringService
+}
+
+func NewRecurringHandler(service *RecurringService) *RecurringHandler {
+	return &RecurringHandler{Service: service}
+}
+
+// CreateRule godoc
+// @Summary Create a recurring rule
+// @Description Create a rule that generates an expense or income on a schedule (DAILY/WEEKLY/MONTHLY/YEARLY every N units)
+// @Tags recurring
+// @Accept json
+// @Produce json
+// @Param rule body dto.RecurringRuleCreateRequest true "Recurring rule details"
+// @Success 201 {object} dto.RecurringRuleResponse "Recurring rule created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Security BearerAuth
+// @Router /recurring [post]
+func (h *RecurringHandler) CreateRule(c *gin.Context) {
+	var req dto.RecurringRuleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	if role == auth.RoleUser && req.UserID != 0 && req.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own recurring rules"})
+		return
+	}
+	if role == auth.RoleUser || req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.StartDate == "" {
+		req.StartDate = time.Now().Format(dateLayout)
+	}
+	if req.Interval == 0 {
+		req.Interval = 1
+	}
+	if req.Currency == "" {
+		req.Currency = "VND"
+	}
+	rule := dbmodel.RecurringRule{
+		UserID:      req.UserID,
+		Amount:      req.Amount,
+		Currency:    strings.ToUpper(req.Currency),
+		Kind:        dbmodel.ExpenseKind(req.Kind),
+		Type:        strings.ToLower(strings.TrimSpace(req.Type)),
+		AccountID:   req.AccountID,
+		Description: req.Description,
+		Frequency:   dbmodel.RecurrenceFrequency(req.Frequency),
+		Interval:    req.Interval,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Paused:      req.Paused,
+	}
+	if err := h.Service.CreateRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toRecurringRuleResponse(&rule))
+}
+
+// ListRules godoc
+// @Summary List recurring rules
+// @Description Get recurring rules, ordered by next run date
+// @Tags recurring
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Success 200 {array} dto.RecurringRuleResponse "List of recurring rules"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /recurring [get]
+func (h *RecurringHandler) ListRules(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	userID := authCtx.UserID
+	role := authCtx.Role
+	var filter dto.RecurringRuleFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if role == auth.RoleUser && filter.UserID != 0 && filter.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own recurring rules"})
+		return
+	}
+	if role == auth.RoleUser || filter.UserID == 0 {
+		filter.UserID = userID
+	}
+	rules, err := h.Service.ListRules(filter.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recurring rules"})
+		return
+	}
+	c.JSON(http.StatusOK, toRecurringRuleResponseList(rules))
+}
+
+// GetRule godoc
+// @Summary Get a recurring rule
+// @Description Get a recurring rule by ID
+// @Tags recurring
+// @Produce json
+// @Param id path int true "Recurring rule ID"
+// @Success 200 {object} dto.RecurringRuleResponse "Recurring rule"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Recurring rule not found"
+// @Security BearerAuth
+// @Router /recurring/{id} [get]
+func (h *RecurringHandler) GetRule(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	rule, err := h.Service.GetRuleByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurring rule not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && rule.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own recurring rules"})
+		return
+	}
+	c.JSON(http.StatusOK, toRecurringRuleResponse(rule))
+}
+
+// UpdateRule godoc
+// @Summary Update a recurring rule
+// @Description Update template or schedule fields of a recurring rule. Changing the schedule, or resuming a paused rule, continues from today without backfilling missed occurrences.
+// @Tags recurring
+// @Accept json
+// @Produce json
+// @Param id path int true "Recurring rule ID"
+// @Param rule body dto.RecurringRuleUpdateRequest true "Recurring rule update details"
+// @Success 200 {object} dto.RecurringRuleResponse "Recurring rule updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Recurring rule not found"
+// @Security BearerAuth
+// @Router /recurring/{id} [put]
+func (h *RecurringHandler) UpdateRule(c *gin.Context) {
+	var req dto.RecurringRuleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	authCtx := auth.GetAuthContext(c)
+	rule, err := h.Service.GetRuleByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurring rule not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && rule.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own recurring rules"})
+		return
+	}
+
+	// Build map of only provided fields; also apply to in-memory object for validation
+	fields := map[string]interface{}{}
+	rescheduled := false
+	if req.Amount != nil {
+		rule.Amount = *req.Amount
+		fields["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		rule.Currency = strings.ToUpper(*req.Currency)
+		fields["currency"] = rule.Currency
+	}
+	if req.Kind != nil {
+		rule.Kind = dbmodel.ExpenseKind(*req.Kind)
+		fields["kind"] = *req.Kind
+	}
+	if req.Type != nil {
+		normalized := strings.ToLower(strings.TrimSpace(*req.Type))
+		rule.Type = normalized
+		fields["type"] = normalized
+	}
+	if req.AccountID != nil {
+		if *req.AccountID == 0 {
+			rule.AccountID = nil
+		} else {
+			rule.AccountID = req.AccountID
+		}
+		fields["account_id"] = rule.AccountID
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+		fields["description"] = *req.Description
+	}
+	if req.Frequency != nil {
+		rule.Frequency = dbmodel.RecurrenceFrequency(*req.Frequency)
+		fields["frequency"] = *req.Frequency
+		rescheduled = true
+	}
+	if req.Interval != nil {
+		rule.Interval = *req.Interval
+		fields["interval"] = *req.Interval
+		rescheduled = true
+	}
+	if req.StartDate != nil {
+		rule.StartDate = *req.StartDate
+		fields["start_date"] = *req.StartDate
+		rescheduled = true
+	}
+	if req.EndDate != nil {
+		rule.EndDate = *req.EndDate
+		fields["end_date"] = *req.EndDate
+		rescheduled = true
+	}
+	if req.Paused != nil {
+		if rule.Paused && !*req.Paused {
+			rescheduled = true
+		}
+		rule.Paused = *req.Paused
+		fields["paused"] = *req.Paused
+	}
+	if len(fields) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+
+	if err := h.Service.UpdateRule(rule, fields, rescheduled); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toRecurringRuleResponse(rule))
+}
+
+// DeleteRule godoc
+// @Summary Delete a recurring rule
+// @Description Delete a recurring rule. Expenses it already generated are kept.
+// @Tags recurring
+// @Produce json
+// @Param id path int true "Recurring rule ID"
+// @Success 200 {object} map[string]interface{} "Recurring rule deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Recurring rule not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /recurring/{id} [delete]
+func (h *RecurringHandler) DeleteRule(c *gin.Context) {
+	authCtx := auth.GetAuthContext(c)
+	rule, err := h.Service.GetRuleByID(utils.ParseUint(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurring rule not found"})
+		return
+	}
+	if authCtx.Role == auth.RoleUser && rule.UserID != authCtx.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own recurring rules"})
+		return
+	}
+	if err := h.Service.DeleteRule(rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recurring rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring rule deleted successfully"})
+}
@@ -0,0 +1,34 @@
+package recurring
+
+import (
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/dto"
+)
+
+func toRecurringRuleResponse(r *dbmodel.RecurringRule) dto.RecurringRuleResponse {
+	return dto.RecurringRuleResponse{
+		ID:              r.ID,
+		UserID:          r.UserID,
+		Amount:          r.Amount,
+		Currency:        r.Currency,
+		Kind:            string(r.Kind),
+		Type:            r.Type,
+		AccountID:       r.AccountID,
+		Description:     r.Description,
+		Frequency:       string(r.Frequency),
+		Interval:        r.Interval,
+		StartDate:       r.StartDate,
+		EndDate:         r.EndDate,
+		NextRun:         r.NextRun,
+		OccurrenceCount: r.OccurrenceCount,
+		Paused:          r.Paused,
+	}
+}
+
+func toRecurringRuleResponseList(rules []dbmodel.RecurringRule) []dto.RecurringRuleResponse {
+	result := make([]dto.RecurringRuleResponse, len(rules))
+	for i := range rules {
+		result[i] = toRecurringRuleResponse(&rules[i])
+	}
+	return result
+}
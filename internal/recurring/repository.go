@@ -0,0 +1,58 @@
+package recurring
+
+import (
+	dbmodel "mindoh-service/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// RecurringRepository handles DB operations for recurring rules
+type RecurringRepository struct {
+	DB *gorm.DB
+}
+
+func NewRecurringRepository(db *gorm.DB) *RecurringRepository {
+	return &RecurringRepository{DB: db}
+}
+
+func (r *RecurringRepository) GetByID(id uint) (*dbmodel.RecurringRule, error) {
+	var rule dbmodel.RecurringRule
+	err := r.DB.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *RecurringRepository) Create(rule *dbmodel.RecurringRule) error {
+	return r.DB.Create(rule).Error
+}
+
+func (r *RecurringRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.DB.Model(&dbmodel.RecurringRule{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *RecurringRepository) Delete(id uint) error {
+	return r.DB.Delete(&dbmodel.RecurringRule{}, id).Error
+}
+
+func (r *RecurringRepository) ListByUser(userID uint) ([]dbmodel.RecurringRule, error) {
+	var rules []dbmodel.RecurringRule
+	query := r.DB.Order("next_run asc, id asc")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// ListDue returns every active rule whose next occurrence is on or before today.
+func (r *RecurringRepository) ListDue(today string) ([]dbmodel.RecurringRule, error) {
+	var rules []dbmodel.RecurringRule
+	err := r.DB.
+		Where("paused = ?", false).
+		Where("next_run <> '' AND next_run <= ?", today).
+		Order("id asc").
+		Find(&rules).Error
+	return rules, err
+}
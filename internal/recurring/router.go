@@ -0,0 +1,21 @@
+package recurring
+
+import (
+	"mindoh-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRecurringRoutes(r *gin.Engine, a auth.IAuthService, service *RecurringService, resolveUser func(string) (uint, error)) {
+	handler := NewRecurringHandler(service)
+
+	group := r.Group("/api/recurring")
+	group.Use(a.AuthMiddleware(resolveUser))
+	{
+		group.POST("/", handler.CreateRule)
+		group.GET("/", handler.ListRules)
+		group.GET("/:id", handler.GetRule)
+		group.PUT("/:id", handler.UpdateRule)
+		group.DELETE("/:id", handler.DeleteRule)
+	}
+}
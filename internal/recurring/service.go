@@ -0,0 +1,243 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	dbmodel "mindoh-service/internal/db"
+	"mindoh-service/internal/expense"
+	"mindoh-service/internal/money"
+
+	"gorm.io/gorm"
+)
+
+const dateLayout = "2006-01-02"
+
+// RecurringService handles business logic for recurring rules and
+// materializes due occurrences into expenses.
+type RecurringService struct {
+	Repo           *RecurringRepository
+	ExpenseService *expense.ExpenseService
+}
+
+func NewRecurringService(repo *RecurringRepository, expenseService *expense.ExpenseService) *RecurringService {
+	return &RecurringService{Repo: repo, ExpenseService: expenseService}
+}
+
+// CreateRule validates the rule and schedules its first occurrence on StartDate.
+func (s *RecurringService) CreateRule(rule *dbmodel.RecurringRule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	if err := s.ExpenseService.ValidateAccount(rule.UserID, rule.AccountID); err != nil {
+		return err
+	}
+	rule.OccurrenceCount = 0
+	rule.NextRun = rule.StartDate
+	if rule.EndDate != "" && rule.NextRun > rule.EndDate {
+		rule.NextRun = ""
+	}
+	return s.Repo.Create(rule)
+}
+
+// UpdateRule updates only the explicitly provided fields of a rule.
+// rule is the in-memory state with the changes already applied; when
+// rescheduled is true the next run is recomputed from today so that
+// missed occurrences are not backfilled.
+func (s *RecurringService) UpdateRule(rule *dbmodel.RecurringRule, fields map[string]interface{}, rescheduled bool) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	if _, ok := fields["account_id"]; ok {
+		if err := s.ExpenseService.ValidateAccount(rule.UserID, rule.AccountID); err != nil {
+			return err
+		}
+	}
+	if rescheduled {
+		if err := reschedule(rule, time.Now().Format(dateLayout)); err != nil {
+			return err
+		}
+		fields["next_run"] = rule.NextRun
+		fields["occurrence_count"] = rule.OccurrenceCount
+	}
+	return s.Repo.UpdateFields(rule.ID, fields)
+}
+
+func (s *RecurringService) GetRuleByID(id uint) (*dbmodel.RecurringRule, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *RecurringService) ListRules(userID uint) ([]dbmodel.RecurringRule, error) {
+	return s.Repo.ListByUser(userID)
+}
+
+func (s *RecurringService) DeleteRule(id uint) error {
+	return s.Repo.Delete(id)
+}
+
+// GenerateDue creates an expense for every occurrence due on or before today
+// and returns how many were created. Occurrences that already exist (including
+// soft-deleted ones) are skipped, so running it repeatedly is safe.
+func (s *RecurringService) GenerateDue(today string) (int, error) {
+	rules, err := s.Repo.ListDue(today)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for i := range rules {
+		n, err := s.materialize(&rules[i], today)
+		created += n
+		if err != nil {
+			slog.Error("recurring: failed to generate occurrences", "rule_id", rules[i].ID, "error", err)
+		}
+	}
+	return created, nil
+}
+
+func (s *RecurringService) materialize(rule *dbmodel.RecurringRule, today string) (int, error) {
+	created := 0
+	for rule.NextRun != "" && rule.NextRun <= today {
+		date := rule.NextRun
+		exists, err := s.ExpenseService.Repo.ExistsRecurringOccurrence(rule.ID, date)
+		if err != nil {
+			return created, err
+		}
+		if !exists {
+			ruleID := rule.ID
+			occurrence := dbmodel.Expense{
+				UserID:          rule.UserID,
+				Amount:          rule.Amount,
+				Currency:        rule.Currency,
+				Kind:            rule.Kind,
+				Type:            rule.Type,
+				AccountID:       rule.AccountID,
+				Description:     rule.Description,
+				Date:            date,
+				RecurringRuleID: &ruleID,
+			}
+			if err := s.ExpenseService.AddExpense(&occurrence, 0); err != nil {
+				// A concurrent run may have inserted the same occurrence.
+				if !errors.Is(err, gorm.ErrDuplicatedKey) {
+					return created, err
+				}
+			} else {
+				created++
+			}
+		}
+		if err := advance(rule); err != nil {
+			return created, err
+		}
+		if err := s.Repo.UpdateFields(rule.ID, map[string]interface{}{
+			"next_run":         rule.NextRun,
+			"occurrence_count": rule.OccurrenceCount,
+		}); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// --- schedule helpers ---
+
+func validateRule(rule *dbmodel.RecurringRule) error {
+	if rule.Kind != dbmodel.ExpenseKindExpense && rule.Kind != dbmodel.ExpenseKindIncome {
+		return errors.New("kind must be expense or income")
+	}
+	if err := money.ValidateCurrency(rule.Currency); err != nil {
+		return err
+	}
+	if err := expense.ValidateAmount(rule.Kind, rule.Amount, rule.Currency); err != nil {
+		return err
+	}
+	switch rule.Frequency {
+	case dbmodel.RecurrenceDaily, dbmodel.RecurrenceWeekly, dbmodel.RecurrenceMonthly, dbmodel.RecurrenceYearly:
+	default:
+		return fmt.Errorf("unsupported frequency: %s", rule.Frequency)
+	}
+	if rule.Interval < 1 {
+		return errors.New("interval must be at least 1")
+	}
+	if _, err := time.Parse(dateLayout, rule.StartDate); err != nil {
+		return errors.New("invalid start_date format, expected YYYY-MM-DD")
+	}
+	if rule.EndDate != "" {
+		if _, err := time.Parse(dateLayout, rule.EndDate); err != nil {
+			return errors.New("invalid end_date format, expected YYYY-MM-DD")
+		}
+		if rule.EndDate < rule.StartDate {
+			return errors.New("end_date must not be before start_date")
+		}
+	}
+	return nil
+}
+
+// occurrenceDate returns the n-th (0-based) occurrence of the rule. Monthly and
+// yearly occurrences are always computed from StartDate and clamped to the end
+// of shorter months, so a rule starting on the 31st does not drift.
+func occurrenceDate(rule *dbmodel.RecurringRule, n int) (string, error) {
+	start, err := time.Parse(dateLayout, rule.StartDate)
+	if err != nil {
+		return "", err
+	}
+	step := n * rule.Interval
+	var t time.Time
+	switch rule.Frequency {
+	case dbmodel.RecurrenceDaily:
+		t = start.AddDate(0, 0, step)
+	case dbmodel.RecurrenceWeekly:
+		t = start.AddDate(0, 0, 7*step)
+	case dbmodel.RecurrenceMonthly:
+		t = addMonthsClamped(start, step)
+	case dbmodel.RecurrenceYearly:
+		t = addMonthsClamped(start, 12*step)
+	default:
+		return "", fmt.Errorf("unsupported frequency: %s", rule.Frequency)
+	}
+	return t.Format(dateLayout), nil
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, months, 0)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// advance moves the rule to its next occurrence, clearing NextRun once EndDate is passed.
+func advance(rule *dbmodel.RecurringRule) error {
+	rule.OccurrenceCount++
+	next, err := occurrenceDate(rule, rule.OccurrenceCount)
+	if err != nil {
+		return err
+	}
+	if rule.EndDate != "" && next > rule.EndDate {
+		next = ""
+	}
+	rule.NextRun = next
+	return nil
+}
+
+// reschedule points the rule at its first occurrence on or after today.
+func reschedule(rule *dbmodel.RecurringRule, today string) error {
+	rule.OccurrenceCount = 0
+	next, err := occurrenceDate(rule, 0)
+	if err != nil {
+		return err
+	}
+	for next < today {
+		rule.OccurrenceCount++
+		if next, err = occurrenceDate(rule, rule.OccurrenceCount); err != nil {
+			return err
+		}
+	}
+	if rule.EndDate != "" && next > rule.EndDate {
+		next = ""
+	}
+	rule.NextRun = next
+	return nil
+}
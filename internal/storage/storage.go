@@ -0,0 +1,44 @@
+// Package storage keeps file contents (such as expense attachments) outside
+// the database. Files are addressed by a slash-separated key; the metadata
+// that points at them lives with the owning record.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"mindoh-service/config"
+)
+
+// ErrNotFound is returned by Get when no object exists for the key.
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores and retrieves file contents by key.
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the storage driver selected in the configuration.
+func New(cfg *config.Config) (Storage, error) {
+	switch strings.ToLower(cfg.Storage.Driver) {
+	case "", "local":
+		dir := cfg.Storage.LocalDir
+		if dir == "" {
+			dir = "data/attachments"
+		}
+		return NewLocalStorage(dir)
+	case "s3":
+		s3 := cfg.Storage.S3
+		return NewS3Storage(s3.Endpoint, s3.Region, s3.Bucket, s3.AccessKey, s3.SecretKey, s3.UseSSL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Storage.Driver)
+	}
+}
@@ -2,14 +2,21 @@ package main
 
 import (
 	"mindoh-service/config"
+	"mindoh-service/internal/account"
+	"mindoh-service/internal/attachment"
 	"mindoh-service/internal/auth"
+	"mindoh-service/internal/budget"
+	"mindoh-service/internal/category"
 	"mindoh-service/internal/currency"
 	"mindoh-service/internal/db"
 	"mindoh-service/internal/expense"
 	"mindoh-service/internal/logger"
 	"mindoh-service/internal/mailer"
+	"mindoh-service/internal/recurring"
+	"mindoh-service/internal/storage"
 	"mindoh-service/internal/user"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -43,11 +50,17 @@ import (
 
 // Services holds all the service instances for the application
 type Services struct {
-	Config         *config.Config
-	DB             *gorm.DB
-	UserService    *user.UserService
-	AuthService    auth.IAuthService
-	ExpenseService *expense.ExpenseService
+	Config            *config.Config
+	DB                *gorm.DB
+	UserService       *user.UserService
+	AuthService       auth.IAuthService
+	AccountService    *account.AccountService
+	CategoryService   *category.CategoryService
+	ExpenseService    *expense.ExpenseService
+	CurrencyService   *currency.CurrencyService
+	AttachmentService *attachment.AttachmentService
+	RecurringService  *recurring.RecurringService
+	BudgetService     *budget.BudgetService
 }
 
 // NewService initializes all services for the application
@@ -82,16 +95,71 @@ func NewService() *Services {
 	// Initialize user service
 	userService := user.NewUserService(dbInstance, mailSvc, cfg.App.URL)
 
-	// Initialize expense service
+	// Initialize exchange rates from the configured providers and start refreshing them
+	rateProviders, err := currency.NewProviders(cfg)
+	if err != nil {
+		logger.L.Error("failed to initialize exchange rate providers", "providers", cfg.ExchangeRates.Providers, "error", err)
+		panic("exchange rate provider initialization failed")
+	}
+	exchangeRateService := currency.NewExchangeRateService(rateProviders, 6*time.Hour)
+	exchangeRateService.Start()
+
+	// Initialize the exchange rate history and start recording daily snapshots
+	rateHistory := currency.NewRateHistory(currency.NewRateRepository(dbInstance), exchangeRateService)
+	currency.NewRateRecorder(rateHistory, time.Hour).Start()
+	currencyService := currency.NewCurrencyService(exchangeRateService, rateHistory, currency.NewFavoriteRepository(dbInstance))
+
+	// Initialize account service
+	accountRepo := account.NewAccountRepository(dbInstance)
+	accountService := account.NewAccountService(accountRepo, rateHistory)
+
+	// Initialize category service
+	categoryRepo := category.NewCategoryRepository(dbInstance)
+	categoryService := category.NewCategoryService(categoryRepo)
+
+	// Initialize attachment storage
+	fileStorage, err := storage.New(cfg)
+	if err != nil {
+		logger.L.Error("failed to initialize file storage", "driver", cfg.Storage.Driver, "error", err)
+		panic("file storage initialization failed")
+	}
+
+	// Initialize expense service and start purging the trash
 	expenseRepo := expense.NewExpenseRepository(dbInstance)
-	expenseService := expense.NewExpenseService(expenseRepo)
+	expenseService := expense.NewExpenseService(expenseRepo, accountRepo, categoryRepo, fileStorage, rateHistory)
+	if days := cfg.Trash.RetentionDays; days > 0 {
+		expense.NewTrashPurger(expenseService, time.Duration(days)*24*time.Hour, time.Hour).Start()
+	}
+
+	// Initialize attachment service
+	maxAttachmentMB := cfg.Attachments.MaxSizeMB
+	if maxAttachmentMB <= 0 {
+		maxAttachmentMB = 10
+	}
+	attachmentRepo := attachment.NewAttachmentRepository(dbInstance)
+	attachmentService := attachment.NewAttachmentService(attachmentRepo, expenseRepo, fileStorage, int64(maxAttachmentMB)<<20)
+
+	// Initialize recurring rules and start the occurrence generator
+	recurringRepo := recurring.NewRecurringRepository(dbInstance)
+	recurringService := recurring.NewRecurringService(recurringRepo, expenseService)
+	recurring.NewGenerator(recurringService, time.Hour).Start()
+
+	// Initialize budget service
+	budgetRepo := budget.NewBudgetRepository(dbInstance)
+	budgetService := budget.NewBudgetService(budgetRepo, expenseService)
 
 	return &Services{
-		Config:         cfg,
-		DB:             dbInstance,
-		AuthService:    authService,
-		UserService:    userService,
-		ExpenseService: expenseService,
+		Config:            cfg,
+		DB:                dbInstance,
+		AuthService:       authService,
+		UserService:       userService,
+		AccountService:    accountService,
+		CategoryService:   categoryService,
+		ExpenseService:    expenseService,
+		CurrencyService:   currencyService,
+		AttachmentService: attachmentService,
+		RecurringService:  recurringService,
+		BudgetService:     budgetService,
 	}
 }
 
@@ -105,10 +173,20 @@ func RegisterRoutes(r *gin.Engine, s *Services) {
 	}
 	// Register user routes
 	user.RegisterUserRoutes(r, s.AuthService, s.UserService, resolveUser)
+	// Register account routes
+	account.RegisterAccountRoutes(r, s.AuthService, s.AccountService, resolveUser)
+	// Register category routes
+	category.RegisterCategoryRoutes(r, s.AuthService, s.CategoryService, resolveUser)
 	// Register expense routes
 	expense.RegisterExpenseRoutes(r, s.AuthService, s.ExpenseService, resolveUser)
+	// Register attachment routes
+	attachment.RegisterAttachmentRoutes(r, s.AuthService, s.AttachmentService, resolveUser)
+	// Register recurring rule routes
+	recurring.RegisterRecurringRoutes(r, s.AuthService, s.RecurringService, resolveUser)
+	// Register budget routes
+	budget.RegisterBudgetRoutes(r, s.AuthService, s.BudgetService, resolveUser)
 	// Register currency routes
-	currency.RegisterCurrencyRoutes(r, s.AuthService, resolveUser)
+	currency.RegisterCurrencyRoutes(r, s.AuthService, s.CurrencyService, resolveUser)
 }
 
 func main() {
@@ -125,7 +203,7 @@ func main() {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Authorization, Accept, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return